@@ -0,0 +1,541 @@
+// Command admin manages the database of the server: it applies and rolls
+// back migrations, lists, purges and dumps rooms, imports word packs and
+// prints usage statistics. It talks to the database directly, so it works
+// while the server is down.
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"codenames/internal/config"
+	"codenames/internal/game"
+	"codenames/internal/model"
+	"codenames/internal/storage"
+	"codenames/internal/storage/sqlite"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+const usage = `usage: admin [-database URL] [-migrations DIR] <command> [arguments]
+
+Commands:
+  migrate up                    apply the pending migrations
+  migrate down [-steps N]       roll back the last N migrations (default 1)
+  migrate force VERSION         mark a failed migration as fixed by hand
+  migrate version               print the schema version
+
+  rooms list                    list the rooms, most recently active first
+  rooms purge -idle DURATION    delete the rooms idle for longer than DURATION
+  rooms delete ID...            delete rooms with everything in them
+
+  dump room ID                  print a room, its players and game as JSON
+  dump game ID                  print a game and its board as JSON
+
+  wordpacks import -name NAME -lang LANG FILE
+                                import a pack of one word per line; blank
+                                lines and lines starting with # are skipped
+  wordpacks list                list the imported word packs
+
+  stats [-since DURATION]       print usage statistics (default 24h)
+
+The database defaults to $DATABASE_URL, the migrations to the server's.
+`
+
+func main() {
+	flags := flag.NewFlagSet("admin", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	databaseURL := flags.String("database", os.Getenv("DATABASE_URL"), "database URL")
+	migrationsPath := flags.String("migrations", defaultMigrationsPath(), "migrations directory")
+	flags.Parse(os.Args[1:])
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	a, err := newAdmin(*databaseURL, *migrationsPath)
+	if err == nil {
+		err = a.run(ctx, flags.Arg(0), flags.Args()[1:])
+		a.close()
+	}
+	if errors.Is(err, errUsage) {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("invalid usage")
+
+// defaultMigrationsPath finds the migrations like the server does, from the
+// backend directory or the repository root.
+func defaultMigrationsPath() string {
+	if _, err := os.Stat("migrations"); os.IsNotExist(err) {
+		return "backend/migrations"
+	}
+	return "migrations"
+}
+
+// admin runs commands against one database. The connection is opened on
+// first use, since migrate opens its own.
+type admin struct {
+	storage        string
+	databaseURL    string
+	migrationsPath string
+	out            io.Writer
+
+	db    *sql.DB // SQLite only
+	store *storage.Store
+	close func()
+}
+
+func newAdmin(databaseURL, migrationsPath string) (*admin, error) {
+	if databaseURL == "" {
+		databaseURL = config.DefaultDatabaseURL
+	}
+	backend, err := config.StorageOf(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	if backend == config.StorageMemory {
+		return nil, errors.New("an in-memory database only exists inside the server")
+	}
+	if backend == config.StorageSQLite {
+		migrationsPath = filepath.Join(migrationsPath, "sqlite")
+	}
+	return &admin{
+		storage:        backend,
+		databaseURL:    databaseURL,
+		migrationsPath: migrationsPath,
+		out:            os.Stdout,
+		close:          func() {},
+	}, nil
+}
+
+func (a *admin) run(ctx context.Context, cmd string, args []string) error {
+	switch cmd {
+	case "migrate":
+		return a.migrate(ctx, args)
+	case "rooms":
+		return a.rooms(ctx, args)
+	case "dump":
+		return a.dump(ctx, args)
+	case "wordpacks":
+		return a.wordPacks(ctx, args)
+	case "stats":
+		return a.stats(ctx, args)
+	}
+	return errUsage
+}
+
+// openStore connects to the database.
+func (a *admin) openStore(ctx context.Context) (storage.Store, error) {
+	if a.store != nil {
+		return *a.store, nil
+	}
+	var store storage.Store
+	switch a.storage {
+	case config.StorageSQLite:
+		db, err := a.openSQLite(ctx)
+		if err != nil {
+			return storage.Store{}, err
+		}
+		store = sqlite.NewStore(db)
+	default:
+		pool, err := storage.NewPool(ctx, a.databaseURL)
+		if err != nil {
+			return storage.Store{}, fmt.Errorf("connect to database: %w", err)
+		}
+		a.close = pool.Close
+		store = storage.NewPostgresStore(pool)
+	}
+	a.store = &store
+	return store, nil
+}
+
+func (a *admin) openSQLite(ctx context.Context) (*sql.DB, error) {
+	if a.db != nil {
+		return a.db, nil
+	}
+	db, err := sqlite.Open(ctx, a.databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	a.db = db
+	a.close = func() { db.Close() }
+	return db, nil
+}
+
+func (a *admin) newMigrate(ctx context.Context) (*migrate.Migrate, error) {
+	if _, err := os.Stat(a.migrationsPath); err != nil {
+		return nil, fmt.Errorf("migrations not found, set -migrations: %w", err)
+	}
+	var m *migrate.Migrate
+	var err error
+	if a.storage == config.StorageSQLite {
+		db, openErr := a.openSQLite(ctx)
+		if openErr != nil {
+			return nil, openErr
+		}
+		m, err = sqlite.NewMigrate(db, a.migrationsPath)
+	} else {
+		m, err = storage.NewMigrate(a.databaseURL, a.migrationsPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.Log = migrateLogger{}
+	// Stop after the current migration on interrupt rather than half-way.
+	go func() {
+		<-ctx.Done()
+		select {
+		case m.GracefulStop <- true:
+		default:
+		}
+	}()
+	return m, nil
+}
+
+// migrateLogger prints the migrations as they are applied.
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) { fmt.Fprintf(os.Stderr, format, v...) }
+
+func (migrateLogger) Verbose() bool { return false }
+
+func (a *admin) migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	var steps int
+	var forceVersion int
+	switch args[0] {
+	case "up", "version":
+		if len(args) != 1 {
+			return errUsage
+		}
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		flags.IntVar(&steps, "steps", 1, "number of migrations to roll back")
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 || steps < 1 {
+			return errUsage
+		}
+	case "force":
+		if len(args) != 2 {
+			return errUsage
+		}
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 0 {
+			return errUsage
+		}
+		forceVersion = v
+	default:
+		return errUsage
+	}
+
+	m, err := a.newMigrate(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Steps(-steps)
+	case "force":
+		err = m.Force(forceVersion)
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Fprintln(a.out, "no change")
+	} else if err != nil {
+		return fmt.Errorf("migrate %s: %w", args[0], err)
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Fprintln(a.out, "no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get schema version: %w", err)
+	}
+	latest, err := storage.LatestMigration(a.migrationsPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "schema at version %d of %d", version, latest)
+	if dirty {
+		fmt.Fprint(a.out, ", dirty: fix the failed migration by hand, then run migrate force")
+	}
+	fmt.Fprintln(a.out)
+	return nil
+}
+
+func (a *admin) rooms(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return errUsage
+		}
+		store, err := a.openStore(ctx)
+		if err != nil {
+			return err
+		}
+		rooms, err := store.Rooms.List(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tPHASE\tPLAYERS\tONLINE\tCREATED\tLAST ACTIVE")
+		for _, r := range rooms {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n", r.ID, r.Phase, r.Players, r.OnlinePlayers,
+				r.CreatedAt.Local().Format(time.DateTime), r.LastActiveAt.Local().Format(time.DateTime))
+		}
+		return w.Flush()
+
+	case "purge":
+		flags := flag.NewFlagSet("rooms purge", flag.ContinueOnError)
+		idle := flags.Duration("idle", 0, "delete the rooms idle for longer than this")
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 || *idle <= 0 {
+			return errUsage
+		}
+		store, err := a.openStore(ctx)
+		if err != nil {
+			return err
+		}
+		stats, err := store.Janitor.DeleteIdleRooms(ctx, time.Now().Add(-*idle))
+		if err != nil {
+			return err
+		}
+		printPurged(a.out, stats)
+		return nil
+
+	case "delete":
+		if len(args) < 2 {
+			return errUsage
+		}
+		store, err := a.openStore(ctx)
+		if err != nil {
+			return err
+		}
+		var total storage.PurgeStats
+		for _, id := range args[1:] {
+			stats, err := store.Rooms.Delete(ctx, id)
+			if errors.Is(err, storage.ErrNotFound) {
+				fmt.Fprintf(os.Stderr, "room %s not found\n", id)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("delete room %s: %w", id, err)
+			}
+			total.Add(stats)
+		}
+		printPurged(a.out, total)
+		return nil
+	}
+	return errUsage
+}
+
+func printPurged(w io.Writer, s storage.PurgeStats) {
+	fmt.Fprintf(w, "deleted %d rooms, %d players, %d games, %d cards, %d chat messages\n",
+		s.Rooms, s.Players, s.Games, s.Cards, s.ChatMessages)
+}
+
+// roomDump is a room as stored, with every card type visible.
+type roomDump struct {
+	Room    model.Room     `json:"room"`
+	Players []model.Player `json:"players"`
+	Game    *model.Game    `json:"game"`
+	Cards   []model.Card   `json:"cards"`
+}
+
+type gameDump struct {
+	Game  model.Game   `json:"game"`
+	Cards []model.Card `json:"cards"`
+}
+
+func (a *admin) dump(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	kind, id := args[0], args[1]
+	if kind != "room" && kind != "game" {
+		return errUsage
+	}
+	store, err := a.openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	var v any
+	switch kind {
+	case "room":
+		room, err := store.Rooms.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("get room %s: %w", id, err)
+		}
+		d := roomDump{Room: room}
+		if d.Players, err = store.Players.GetByRoomID(ctx, id); err != nil {
+			return err
+		}
+		g, err := store.Games.GetActiveByRoomID(ctx, id)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+		if err == nil {
+			d.Game = &g
+			if d.Cards, err = store.Games.GetCardsByGameID(ctx, g.ID); err != nil {
+				return err
+			}
+		}
+		v = d
+	case "game":
+		g, err := store.Games.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("get game %s: %w", id, err)
+		}
+		d := gameDump{Game: g}
+		if d.Cards, err = store.Games.GetCardsByGameID(ctx, id); err != nil {
+			return err
+		}
+		v = d
+	}
+	enc := json.NewEncoder(a.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (a *admin) wordPacks(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "import":
+		flags := flag.NewFlagSet("wordpacks import", flag.ContinueOnError)
+		name := flags.String("name", "", "name of the pack")
+		lang := flags.String("lang", "", "language of the words, e.g. ru")
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 1 {
+			return errUsage
+		}
+		pack := model.WordPack{Language: *lang}
+		var err error
+		if pack.Words, err = readWords(flags.Arg(0)); err != nil {
+			return err
+		}
+		if err := game.ValidateWordPack(*name, pack); err != nil {
+			return err
+		}
+		store, err := a.openStore(ctx)
+		if err != nil {
+			return err
+		}
+		if err := store.WordPacks.Save(ctx, *name, pack); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.out, "imported %d words into %s, restart the server to use them\n", len(pack.Words), *name)
+		return nil
+
+	case "list":
+		if len(args) != 1 {
+			return errUsage
+		}
+		store, err := a.openStore(ctx)
+		if err != nil {
+			return err
+		}
+		packs, err := store.WordPacks.List(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tLANGUAGE\tWORDS")
+		for name, pack := range game.WordPacks {
+			fmt.Fprintf(w, "%s\t%s\t%d\t(built in)\n", name, pack.Language, len(pack.Words))
+		}
+		for name, pack := range packs {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", name, pack.Language, len(pack.Words))
+		}
+		return w.Flush()
+	}
+	return errUsage
+}
+
+// readWords reads a word pack file, or stdin for "-": one word per line,
+// skipping blank lines and # comments. Words are upper-cased like the
+// built-in ones and duplicates dropped.
+func readWords(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open word pack: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var words []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		w := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if w == "" || strings.HasPrefix(w, "#") || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read word pack: %w", err)
+	}
+	return words, nil
+}
+
+func (a *admin) stats(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	since := flags.Duration("since", 24*time.Hour, "count the rooms and games created since")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || *since <= 0 {
+		return errUsage
+	}
+	store, err := a.openStore(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-*since)
+	s, err := store.Stats.Usage(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	from := cutoff.Local().Format(time.DateTime)
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "rooms\t%d\t%d active, %d created since %s\n", s.Rooms, s.ActiveRooms, s.RoomsSince, from)
+	fmt.Fprintf(w, "players\t%d\t%d online\n", s.Players, s.OnlinePlayers)
+	fmt.Fprintf(w, "users\t%d\n", s.Users)
+	fmt.Fprintf(w, "games\t%d\t%d playing, %d started since %s\n", s.Games, s.GamesPlaying, s.GamesSince, from)
+	fmt.Fprintf(w, "chat messages\t%d\n", s.ChatMessages)
+	fmt.Fprintf(w, "word packs\t%d\timported\n", s.WordPacks)
+	return w.Flush()
+}
@@ -2,70 +2,156 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"time"
 
+	"codenames/internal/auth"
 	"codenames/internal/config"
 	"codenames/internal/game"
 	"codenames/internal/handler"
 	"codenames/internal/hub"
+	"codenames/internal/janitor"
+	"codenames/internal/logging"
+	"codenames/internal/metrics"
+	"codenames/internal/ratelimit"
 	"codenames/internal/storage"
+	"codenames/internal/storage/memory"
+	"codenames/internal/storage/sqlite"
+	"codenames/internal/tracing"
 )
 
 func main() {
 	cfg := config.Load()
 
-	// Run migrations
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracesExporter)
+	if err != nil {
+		logging.Fatal("tracing setup failed", "err", err)
+	}
+
 	migrationsPath := "migrations"
 	if _, err := os.Stat(migrationsPath); os.IsNotExist(err) {
 		migrationsPath = "backend/migrations"
 	}
-	if err := storage.RunMigrations(cfg.DatabaseURL, migrationsPath); err != nil {
-		log.Printf("warning: migrations: %v", err)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	var store storage.Store
+	var schemaPath string
+	switch cfg.Storage {
+	case config.StorageMemory:
+		slog.Warn("using in-memory storage, data is lost on restart")
+		store = memory.NewStore()
+	case config.StorageSQLite:
+		db, err := sqlite.Open(ctx, cfg.DatabaseURL)
+		if err != nil {
+			logging.Fatal("failed to open database", "err", err)
+		}
+		defer db.Close()
+		schemaPath = filepath.Join(migrationsPath, "sqlite")
+		if cfg.MigrateOnStart {
+			if err := sqlite.RunMigrations(db, schemaPath); err != nil {
+				logging.Fatal("migrations failed, fix them with the admin migrate command", "err", err)
+			}
+		}
+		store = sqlite.NewStore(db)
+	default:
+		schemaPath = migrationsPath
+		if cfg.MigrateOnStart {
+			if err := storage.RunMigrations(cfg.DatabaseURL, schemaPath); err != nil {
+				logging.Fatal("migrations failed, fix them with the admin migrate command", "err", err)
+			}
+		}
+
+		// Connect to DB
+		pool, err := storage.NewPool(ctx, cfg.DatabaseURL)
+		if err != nil {
+			logging.Fatal("failed to connect to database", "err", err)
+		}
+		defer pool.Close()
+		store = storage.NewPostgresStore(pool)
+	}
+	if !cfg.MigrateOnStart && schemaPath != "" {
+		slog.Info("MIGRATE_ON_START is false, not applying migrations")
 	}
+	store = metrics.InstrumentStore(store)
 
-	// Connect to DB
-	ctx := context.Background()
-	pool, err := storage.NewPool(ctx, cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+	// Imported packs are missing until the migrations are applied, the
+	// built-in ones still work.
+	if err := game.LoadWordPacks(ctx, store.WordPacks); err != nil {
+		slog.Error("word packs not loaded", "err", err)
 	}
-	defer pool.Close()
 
-	// Init repos
-	roomRepo := storage.NewRoomRepo(pool)
-	playerRepo := storage.NewPlayerRepo(pool)
-	gameRepo := storage.NewGameRepo(pool)
+	signer := auth.NewSigner(cfg.AuthSecret, cfg.TokenTTL)
 
 	// Init engine
-	engine := game.NewEngine(gameRepo, playerRepo)
+	engine := game.NewEngine(store.Games, store.Players, store.Ratings)
 
 	// Init hub
-	h := hub.NewHub(roomRepo, playerRepo, gameRepo, engine)
-	go h.Run()
+	h := hub.NewHub(store.Rooms, store.Players, store.Games, store.Chat, engine, store.Notifier, hub.Config{
+		PingInterval: cfg.PingInterval,
+		PongTimeout:  cfg.PongTimeout,
+		OfflineGrace: cfg.PresenceGrace,
+
+		MaxMessageSize:     cfg.MaxMessageSize,
+		MessagesPerIP:      cfg.MessagesPerIP,
+		MessagesPerSession: cfg.MessagesPerSession,
+	})
+	// Background work stops after the hub, and before the database closes.
+	var background sync.WaitGroup
+	background.Add(2)
+	go func() {
+		defer background.Done()
+		h.RunPeers(ctx)
+	}()
+
+	// Init janitor
+	j := janitor.New(store.Janitor, janitor.Config{
+		RoomRetention: cfg.RoomRetention,
+		GameRetention: cfg.GameRetention,
+		Interval:      cfg.JanitorInterval,
+	})
+	go func() {
+		defer background.Done()
+		j.Run(ctx)
+	}()
+	metrics.RegisterJanitor(j)
 
 	// Init handlers
-	roomHandler := handler.NewRoomHandler(roomRepo, playerRepo, gameRepo)
-	playerHandler := handler.NewPlayerHandler(playerRepo)
-	wsHandler := handler.NewWSHandler(h, playerRepo)
+	roomHandler := handler.NewRoomHandler(store.Rooms, store.Players, store.Games)
+	playerHandler := handler.NewPlayerHandler(store.Players, signer)
+	wsHandler := handler.NewWSHandler(h, store.Players, signer)
+	leaderboardHandler := handler.NewLeaderboardHandler(store.Ratings)
+	authHandler := handler.NewAuthHandler(store.Users, signer)
+	adminHandler := handler.NewAdminHandler(h, store.Rooms, store.Players, store.Games, cfg.AdminToken)
+	gameHandler := handler.NewGameHandler(h, store.Rooms, store.Players, store.Games, signer)
+	healthHandler := handler.NewHealthHandler(healthChecks(store.Health, h, schemaPath)...)
+
+	authLimit := handler.NewRateLimiter(signer, cfg.AuthPerIP, ratelimit.Limit{})
+	roomLimit := handler.NewRateLimiter(signer, cfg.RoomsPerIP, cfg.RoomsPerSession)
+	playerLimit := handler.NewRateLimiter(signer, cfg.PlayersPerIP, cfg.PlayersPerSession)
 
 	// Init router
-	r := handler.NewRouter(roomHandler, playerHandler, wsHandler)
+	r := handler.NewRouter(roomHandler, playerHandler, wsHandler, leaderboardHandler, authHandler, healthHandler, adminHandler, gameHandler, authLimit, roomLimit, playerLimit, cfg.TrustProxy)
 
 	// Start server
 	srv := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: r,
+		Addr:     ":" + cfg.Port,
+		Handler:  r,
+		ErrorLog: slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
 	}
 
 	go func() {
-		log.Printf("server starting on :%s", cfg.Port)
+		slog.Info("server starting", "port", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+			logging.Fatal("server error", "err", err)
 		}
 	}()
 
@@ -73,12 +159,61 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("shutting down...")
+	slog.Info("shutting down")
+
+	// Fail readiness and refuse new WebSocket connections while the load
+	// balancer notices, then stop accepting requests.
+	healthHandler.Drain()
+	time.Sleep(cfg.DrainDelay)
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("shutdown error: %v", err)
+		logging.Fatal("shutdown error", "err", err)
+	}
+	// The server doesn't track WebSocket connections; the hub closes them.
+	if err := h.Shutdown(shutdownCtx); err != nil {
+		slog.Error("hub shutdown failed", "err", err)
+	}
+	stop()
+	background.Wait()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("tracing shutdown failed", "err", err)
+	}
+	slog.Info("server stopped")
+}
+
+// healthChecks returns the readiness checks: the database answers, its
+// schema is at the latest migration in schemaPath, if any, and the hub
+// relays messages between instances.
+func healthChecks(db storage.HealthStore, h *hub.Hub, schemaPath string) []handler.HealthCheck {
+	checks := []handler.HealthCheck{
+		{Name: "database", Check: db.Ping},
+		{Name: "hub", Check: func(context.Context) error {
+			if !h.Running() {
+				return errors.New("hub is not running")
+			}
+			return nil
+		}},
 	}
-	log.Println("server stopped")
+	if schemaPath == "" {
+		return checks
+	}
+	return append(checks, handler.HealthCheck{Name: "migrations", Check: func(ctx context.Context) error {
+		want, err := storage.LatestMigration(schemaPath)
+		if err != nil {
+			return err
+		}
+		version, dirty, err := db.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migration %d failed", version)
+		}
+		if version < want {
+			return fmt.Errorf("schema at version %d, want %d", version, want)
+		}
+		return nil
+	}})
 }
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TokenFromRequest reads a bearer token from the Authorization header,
+// falling back to the token query parameter used by WebSocket clients,
+// which cannot set headers.
+func TokenFromRequest(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		if token, ok := strings.CutPrefix(h, "Bearer "); ok {
+			return token
+		}
+	}
+	return r.URL.Query().Get("token")
+}
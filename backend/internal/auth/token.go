@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Claims identify the holder of a token. SessionID is the identity used by
+// players and ratings; UserID is empty for guests.
+type Claims struct {
+	SessionID string `json:"sid"`
+	UserID    string `json:"uid,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (c Claims) IsGuest() bool {
+	return c.UserID == ""
+}
+
+// Signer issues and verifies HMAC-SHA256 signed tokens of the form
+// base64url(claims) "." base64url(signature).
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	return &Signer{secret: secret, ttl: ttl}
+}
+
+func (s *Signer) Issue(sessionID, userID string) (string, error) {
+	claims := Claims{
+		SessionID: sessionID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.ttl).Unix(),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("encode claims: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+func (s *Signer) Verify(token string) (Claims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.sign(encoded)) {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.SessionID == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return Claims{}, ErrExpiredToken
+	}
+	return claims, nil
+}
+
+func (s *Signer) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// NewSessionID returns a random identifier for a new guest session.
+func NewSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,45 +1,48 @@
 package game
 
 import (
+	"errors"
+	"fmt"
 	"math/rand"
 
 	"codenames/internal/model"
 )
 
-// GenerateBoard creates 25 cards for a new game.
-// firstTeam gets 9 cards, the other gets 8, 7 neutral, 1 assassin.
-func GenerateBoard(gameID string, firstTeam model.Team) []model.Card {
-	words := pickRandomWords(25)
+// GenerateBoard creates the cards for a new game according to the room settings.
+// firstTeam gets spec.FirstTeamCards, the other team spec.SecondTeamCards,
+// then spec.Assassins assassins and the rest neutral. It fails if the
+// selected packs hold fewer words than the board.
+func GenerateBoard(gameID string, firstTeam model.Team, settings model.RoomSettings) ([]model.Card, error) {
+	spec := settings.Board
+	pool := wordPool(settings.WordPacks)
+	if len(pool) < spec.Size {
+		return nil, fmt.Errorf("not enough words in selected packs for board size: %d, need %d", len(pool), spec.Size)
+	}
+	words := pickRandomWords(pool, spec.Size)
 
 	second := firstTeam.Opposite()
-	types := make([]model.CardType, 25)
+	types := make([]model.CardType, 0, spec.Size)
 
-	// 9 for first team
-	idx := 0
-	for i := 0; i < 9; i++ {
-		types[idx] = model.CardType(firstTeam)
-		idx++
+	for i := 0; i < spec.FirstTeamCards; i++ {
+		types = append(types, model.CardType(firstTeam))
+	}
+	for i := 0; i < spec.SecondTeamCards; i++ {
+		types = append(types, model.CardType(second))
 	}
-	// 8 for second team
-	for i := 0; i < 8; i++ {
-		types[idx] = model.CardType(second)
-		idx++
+	for i := 0; i < spec.Assassins; i++ {
+		types = append(types, model.CardTypeAssassin)
 	}
-	// 7 neutral
-	for i := 0; i < 7; i++ {
-		types[idx] = model.CardTypeNeutral
-		idx++
+	for len(types) < spec.Size {
+		types = append(types, model.CardTypeNeutral)
 	}
-	// 1 assassin
-	types[idx] = model.CardTypeAssassin
 
 	// shuffle types
 	rand.Shuffle(len(types), func(i, j int) {
 		types[i], types[j] = types[j], types[i]
 	})
 
-	cards := make([]model.Card, 25)
-	for i := 0; i < 25; i++ {
+	cards := make([]model.Card, spec.Size)
+	for i := 0; i < spec.Size; i++ {
 		cards[i] = model.Card{
 			GameID:   gameID,
 			Word:     words[i],
@@ -47,14 +50,59 @@ func GenerateBoard(gameID string, firstTeam model.Team) []model.Card {
 			Position: i,
 		}
 	}
-	return cards
+	return cards, nil
 }
 
-func pickRandomWords(n int) []string {
-	perm := rand.Perm(len(RussianWords))
+// wordPool merges the selected packs, dropping duplicate words.
+func wordPool(packs []string) []string {
+	seen := make(map[string]bool)
+	var pool []string
+	for _, name := range packs {
+		for _, w := range WordPacks[name].Words {
+			if !seen[w] {
+				seen[w] = true
+				pool = append(pool, w)
+			}
+		}
+	}
+	return pool
+}
+
+func pickRandomWords(pool []string, n int) []string {
+	perm := rand.Perm(len(pool))
 	words := make([]string, n)
 	for i := 0; i < n; i++ {
-		words[i] = RussianWords[perm[i]]
+		words[i] = pool[perm[i]]
 	}
 	return words
 }
+
+// ValidateBoard checks a board made elsewhere: one card at each position
+// from 0, within the board sizes rooms allow, every card with a word and a
+// type, and each team with at least one card.
+func ValidateBoard(cards []model.Card) error {
+	if len(cards) < minBoardSize || len(cards) > maxBoardSize {
+		return fmt.Errorf("board size must be between %d and %d", minBoardSize, maxBoardSize)
+	}
+	seen := make([]bool, len(cards))
+	teams := make(map[model.CardType]int)
+	for _, c := range cards {
+		if c.Position < 0 || c.Position >= len(cards) || seen[c.Position] {
+			return fmt.Errorf("invalid or duplicate card position %d", c.Position)
+		}
+		seen[c.Position] = true
+		if c.Word == "" {
+			return fmt.Errorf("card %d has no word", c.Position)
+		}
+		switch c.CardType {
+		case model.CardTypeRed, model.CardTypeBlue, model.CardTypeNeutral, model.CardTypeAssassin:
+			teams[c.CardType]++
+		default:
+			return fmt.Errorf("card %d has invalid type %q", c.Position, c.CardType)
+		}
+	}
+	if teams[model.CardTypeRed] == 0 || teams[model.CardTypeBlue] == 0 {
+		return errors.New("each team needs at least 1 card")
+	}
+	return nil
+}
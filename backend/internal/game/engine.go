@@ -5,18 +5,35 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 
+	"codenames/internal/logging"
+	"codenames/internal/metrics"
 	"codenames/internal/model"
+	"codenames/internal/protocol"
 	"codenames/internal/storage"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("codenames/internal/game")
+
+var ErrNotPlaying = errors.New("game is not in playing phase")
+
+// ErrInvalidSettings is returned when a game can't be started with the room
+// settings, e.g. after a word pack the room uses was replaced by a smaller one.
+var ErrInvalidSettings = errors.New("invalid room settings")
+
 type Engine struct {
-	gameRepo   *storage.GameRepo
-	playerRepo *storage.PlayerRepo
+	gameRepo   storage.GameStore
+	playerRepo storage.PlayerStore
+	ratingRepo storage.RatingStore
 }
 
-func NewEngine(gameRepo *storage.GameRepo, playerRepo *storage.PlayerRepo) *Engine {
-	return &Engine{gameRepo: gameRepo, playerRepo: playerRepo}
+func NewEngine(gameRepo storage.GameStore, playerRepo storage.PlayerStore, ratingRepo storage.RatingStore) *Engine {
+	return &Engine{gameRepo: gameRepo, playerRepo: playerRepo, ratingRepo: ratingRepo}
 }
 
 // CanStartGame checks if the room has enough players to start.
@@ -49,45 +66,169 @@ func (e *Engine) CanStartGame(players []model.Player) error {
 	return nil
 }
 
-// StartGame creates a new game with a random first team and generated board.
-func (e *Engine) StartGame(ctx context.Context, roomID string) (model.Game, []model.Card, error) {
+// StartGame creates a new game with a random first team and a board built from the room settings.
+func (e *Engine) StartGame(ctx context.Context, roomID string, settings model.RoomSettings, players []model.Player) (model.Game, []model.Card, error) {
+	ctx, span := tracer.Start(ctx, "engine.StartGame", trace.WithAttributes(attribute.String("room_id", roomID)))
+	defer span.End()
+
+	// The settings were checked when stored, but word packs may have
+	// changed since.
+	if err := ValidateSettings(settings); err != nil {
+		return model.Game{}, nil, fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
 	firstTeam := model.TeamRed
 	if rand.Intn(2) == 0 {
 		firstTeam = model.TeamBlue
 	}
+	board, err := GenerateBoard("", firstTeam, settings)
+	if err != nil {
+		return model.Game{}, nil, fmt.Errorf("%w: %w", ErrInvalidSettings, err)
+	}
 
-	game, err := e.gameRepo.Create(ctx, roomID, firstTeam)
+	var roster []model.GamePlayer
+	for _, p := range players {
+		if p.Team != "" {
+			roster = append(roster, model.GamePlayer{PlayerID: p.ID, Name: p.Name, Team: p.Team, Role: p.Role})
+		}
+	}
+	g, cards, err := e.gameRepo.CreateWithCards(ctx, roomID, firstTeam, board, roster)
 	if err != nil {
-		return model.Game{}, nil, err
+		return g, cards, err
 	}
+	metrics.GamesStarted.Inc()
+	return g, cards, nil
+}
 
-	cards := GenerateBoard(game.ID, firstTeam)
-	if err := e.gameRepo.CreateCards(ctx, cards); err != nil {
-		return model.Game{}, nil, err
+// GiveClue sets the current clue and number for the active team.
+func (e *Engine) GiveClue(ctx context.Context, game model.Game, player model.Player, clue string, number int) (model.Game, error) {
+	ctx, span := tracer.Start(ctx, "engine.GiveClue", trace.WithAttributes(attribute.String("game_id", game.ID)))
+	defer span.End()
+
+	move := model.Move{Kind: model.MoveClue, Team: game.CurrentTeam, Player: player.Name, Clue: clue, Number: number}
+	if err := giveClue(&game, clue, number); err != nil {
+		return game, err
+	}
+	if err := e.save(ctx, &game, move); err != nil {
+		return game, err
 	}
+	return game, nil
+}
 
-	// re-read cards to get IDs
-	cards, err = e.gameRepo.GetCardsByGameID(ctx, game.ID)
-	if err != nil {
-		return model.Game{}, nil, err
+// GuessCard reveals a card and returns the updated game state.
+// Returns (game, cards, error). The game may be finished after this.
+func (e *Engine) GuessCard(ctx context.Context, game model.Game, cards []model.Card, cardID string, player model.Player) (model.Game, []model.Card, error) {
+	ctx, span := tracer.Start(ctx, "engine.GuessCard", trace.WithAttributes(
+		attribute.String("game_id", game.ID),
+		attribute.String("card_id", cardID),
+	))
+	defer span.End()
+
+	cardIdx := slices.IndexFunc(cards, func(c model.Card) bool { return c.ID == cardID })
+	if cardIdx < 0 {
+		return game, cards, errors.New("card not found")
 	}
+	move := model.Move{Kind: model.MoveGuess, Team: game.CurrentTeam, Player: player.Name, Position: cards[cardIdx].Position}
+	if err := guessCard(&game, cards, cardIdx, player.Team); err != nil {
+		return game, cards, err
+	}
+	card := cards[cardIdx]
 
+	if err := e.save(ctx, &game, move, card); err != nil {
+		return game, cards, err
+	}
+	if game.Phase == model.PhaseFinished {
+		reason := protocol.ReasonAllCards
+		if card.CardType == model.CardTypeAssassin {
+			reason = protocol.ReasonAssassin
+		}
+		metrics.GamesFinished.WithLabelValues(string(game.Winner), reason).Inc()
+		// A failed rating update is logged but does not undo the result.
+		if err := e.recordRatings(ctx, game); err != nil {
+			logging.FromContext(ctx).Error("record ratings", "game_id", game.ID, "err", err)
+		}
+	}
 	return game, cards, nil
 }
 
-// GiveClue sets the current clue and number for the active team.
-func (e *Engine) GiveClue(ctx context.Context, game model.Game, clue string, number int) (model.Game, error) {
+// EndGuessing ends the current team's guessing phase.
+func (e *Engine) EndGuessing(ctx context.Context, game model.Game, player model.Player) (model.Game, error) {
+	ctx, span := tracer.Start(ctx, "engine.EndGuessing", trace.WithAttributes(attribute.String("game_id", game.ID)))
+	defer span.End()
+
+	move := model.Move{Kind: model.MoveEndGuessing, Team: game.CurrentTeam, Player: player.Name}
+	if err := endGuessing(&game); err != nil {
+		return game, err
+	}
+	if err := e.save(ctx, &game, move); err != nil {
+		return game, fmt.Errorf("end guessing: %w", err)
+	}
+	return game, nil
+}
+
+// ForceFinish ends a game being played, with the given winner or none. An
+// operator's decision doesn't count towards ratings.
+func (e *Engine) ForceFinish(ctx context.Context, game model.Game, winner model.Team) (model.Game, error) {
+	ctx, span := tracer.Start(ctx, "engine.ForceFinish", trace.WithAttributes(attribute.String("game_id", game.ID)))
+	defer span.End()
+
+	move := model.Move{Kind: model.MoveFinish, Team: game.CurrentTeam, Winner: winner}
+	if err := forceFinish(&game, winner); err != nil {
+		return game, err
+	}
+	if err := e.save(ctx, &game, move); err != nil {
+		return game, fmt.Errorf("force finish: %w", err)
+	}
+	metrics.GamesFinished.WithLabelValues(string(game.Winner), protocol.ReasonEndedByAdmin).Inc()
+	return game, nil
+}
+
+// save persists the game together with the move and the cards it revealed,
+// in one transaction, and moves the game to its new version. It returns
+// storage.ErrConflict if the game changed since it was read.
+func (e *Engine) save(ctx context.Context, game *model.Game, move model.Move, revealed ...model.Card) error {
+	version, err := e.gameRepo.Save(ctx, *game, move, revealed)
+	if err != nil {
+		return err
+	}
+	game.Version = version
+	return nil
+}
+
+func (e *Engine) recordRatings(ctx context.Context, game model.Game) error {
+	players, err := e.playerRepo.GetByRoomID(ctx, game.RoomID)
+	if err != nil {
+		return err
+	}
+	sessionIDs := make([]string, 0, len(players))
+	for _, p := range players {
+		sessionIDs = append(sessionIDs, p.SessionID)
+	}
+	current, err := e.ratingRepo.GetBySessions(ctx, sessionIDs)
+	if err != nil {
+		return err
+	}
+	changes := ComputeRatingChanges(players, current, game.Winner)
+	if len(changes) == 0 {
+		return nil
+	}
+	return e.ratingRepo.ApplyGameResult(ctx, game.ID, game.RoomID, changes)
+}
+
+// The rules below change the game in memory; the engine persists what they
+// did and Replay applies them to recorded moves.
+
+func giveClue(game *model.Game, clue string, number int) error {
 	if game.Phase != model.PhasePlaying {
-		return game, errors.New("game is not in playing phase")
+		return ErrNotPlaying
 	}
 	if game.CurrentClue != "" {
-		return game, errors.New("already gave a clue this turn")
+		return errors.New("already gave a clue this turn")
 	}
 	if clue == "" {
-		return game, errors.New("clue cannot be empty")
+		return errors.New("clue cannot be empty")
 	}
 	if number < 0 {
-		return game, errors.New("number must be >= 0")
+		return errors.New("number must be >= 0")
 	}
 
 	game.CurrentClue = clue
@@ -97,108 +238,77 @@ func (e *Engine) GiveClue(ctx context.Context, game model.Game, clue string, num
 	} else {
 		game.GuessesLeft = number + 1
 	}
-
-	if err := e.gameRepo.Update(ctx, game); err != nil {
-		return game, err
-	}
-	return game, nil
+	return nil
 }
 
-// GuessCard reveals a card and returns the updated game state.
-// Returns (game, cards, error). The game may be finished after this.
-func (e *Engine) GuessCard(ctx context.Context, game model.Game, cards []model.Card, cardID string, team model.Team) (model.Game, []model.Card, error) {
+// guessCard reveals cards[cardIdx].
+func guessCard(game *model.Game, cards []model.Card, cardIdx int, team model.Team) error {
 	if game.Phase != model.PhasePlaying {
-		return game, cards, errors.New("game is not in playing phase")
+		return ErrNotPlaying
 	}
 	if game.CurrentClue == "" {
-		return game, cards, errors.New("no clue given yet")
+		return errors.New("no clue given yet")
 	}
 	if team != game.CurrentTeam {
-		return game, cards, errors.New("not your team's turn")
+		return errors.New("not your team's turn")
 	}
 	if game.GuessesLeft <= 0 {
-		return game, cards, errors.New("no guesses left")
-	}
-
-	// Find the card
-	var card *model.Card
-	cardIdx := -1
-	for i := range cards {
-		if cards[i].ID == cardID {
-			card = &cards[i]
-			cardIdx = i
-			break
-		}
-	}
-	if card == nil {
-		return game, cards, errors.New("card not found")
+		return errors.New("no guesses left")
 	}
+	card := &cards[cardIdx]
 	if card.Revealed {
-		return game, cards, errors.New("card already revealed")
+		return errors.New("card already revealed")
 	}
 
 	// Reveal the card
 	card.Revealed = true
 	card.RevealedBy = team
-	if err := e.gameRepo.RevealCard(ctx, cardID, team); err != nil {
-		return game, cards, err
-	}
-	cards[cardIdx] = *card
 
-	// Check assassin
 	if card.CardType == model.CardTypeAssassin {
-		game.Phase = model.PhaseFinished
-		game.Winner = team.Opposite()
-		if err := e.gameRepo.SetFinished(ctx, game.ID, game.Winner); err != nil {
-			return game, cards, err
-		}
-		return game, cards, nil
-	}
-
-	// Check if a team has all their cards revealed
-	if winner := checkAllRevealed(cards); winner != "" {
-		game.Phase = model.PhaseFinished
-		game.Winner = winner
-		if err := e.gameRepo.SetFinished(ctx, game.ID, game.Winner); err != nil {
-			return game, cards, err
-		}
-		return game, cards, nil
-	}
-
-	// Determine what happens next
-	if model.CardType(team) == card.CardType {
+		// Check assassin
+		finish(game, team.Opposite())
+	} else if winner := checkAllRevealed(cards); winner != "" {
+		// Check if a team has all their cards revealed
+		finish(game, winner)
+	} else if model.CardType(team) == card.CardType {
 		// Correct guess
 		game.GuessesLeft--
 		if game.GuessesLeft <= 0 {
-			e.endTurn(&game)
+			endTurn(game)
 		}
 	} else {
 		// Wrong guess (neutral or opponent's card) — end turn
-		e.endTurn(&game)
-	}
-
-	if err := e.gameRepo.Update(ctx, game); err != nil {
-		return game, cards, err
+		endTurn(game)
 	}
-	return game, cards, nil
+	return nil
 }
 
-// EndGuessing ends the current team's guessing phase.
-func (e *Engine) EndGuessing(ctx context.Context, game model.Game) (model.Game, error) {
+func endGuessing(game *model.Game) error {
 	if game.Phase != model.PhasePlaying {
-		return game, errors.New("game is not in playing phase")
+		return ErrNotPlaying
 	}
 	if game.CurrentClue == "" {
-		return game, errors.New("no clue given yet")
+		return errors.New("no clue given yet")
 	}
-	e.endTurn(&game)
-	if err := e.gameRepo.Update(ctx, game); err != nil {
-		return game, fmt.Errorf("end guessing: %w", err)
+	endTurn(game)
+	return nil
+}
+
+func forceFinish(game *model.Game, winner model.Team) error {
+	if game.Phase != model.PhasePlaying {
+		return ErrNotPlaying
 	}
-	return game, nil
+	finish(game, winner)
+	return nil
+}
+
+// finish ends the game with the given winner.
+func finish(game *model.Game, winner model.Team) {
+	game.Phase = model.PhaseFinished
+	game.Winner = winner
 }
 
-func (e *Engine) endTurn(game *model.Game) {
+func endTurn(game *model.Game) {
 	game.CurrentTeam = game.CurrentTeam.Opposite()
 	game.CurrentClue = ""
 	game.CurrentNumber = 0
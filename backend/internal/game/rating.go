@@ -0,0 +1,74 @@
+package game
+
+import (
+	"math"
+
+	"codenames/internal/model"
+)
+
+const (
+	DefaultRating = 1500.0
+	ratingK       = 32.0
+)
+
+type ratingKey struct {
+	sessionID string
+	role      model.Role
+}
+
+// ComputeRatingChanges applies a team Elo update to every player who took part
+// in a finished game. A team's strength is the mean rating of its members,
+// each measured in the role they played.
+func ComputeRatingChanges(players []model.Player, current []model.Rating, winner model.Team) []model.RatingChange {
+	ratings := make(map[ratingKey]float64)
+	for _, r := range current {
+		ratings[ratingKey{r.SessionID, r.Role}] = r.Rating
+	}
+	ratingOf := func(p model.Player) float64 {
+		if r, ok := ratings[ratingKey{p.SessionID, p.Role}]; ok {
+			return r
+		}
+		return DefaultRating
+	}
+
+	sum := make(map[model.Team]float64)
+	count := make(map[model.Team]int)
+	var participants []model.Player
+	for _, p := range players {
+		if p.Team != model.TeamRed && p.Team != model.TeamBlue {
+			continue
+		}
+		if p.Role != model.RoleSpymaster && p.Role != model.RoleOperative {
+			continue
+		}
+		participants = append(participants, p)
+		sum[p.Team] += ratingOf(p)
+		count[p.Team]++
+	}
+	if count[model.TeamRed] == 0 || count[model.TeamBlue] == 0 {
+		return nil
+	}
+
+	changes := make([]model.RatingChange, 0, len(participants))
+	for _, p := range participants {
+		own := sum[p.Team] / float64(count[p.Team])
+		opp := sum[p.Team.Opposite()] / float64(count[p.Team.Opposite()])
+		expected := 1 / (1 + math.Pow(10, (opp-own)/400))
+
+		won := p.Team == winner
+		score := 0.0
+		if won {
+			score = 1
+		}
+		before := ratingOf(p)
+		changes = append(changes, model.RatingChange{
+			SessionID:  p.SessionID,
+			PlayerName: p.Name,
+			Role:       p.Role,
+			Before:     before,
+			After:      before + ratingK*(score-expected),
+			Won:        won,
+		})
+	}
+	return changes
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"codenames/internal/model"
+)
+
+// Replay plays the moves from the start of a game on its board and returns
+// the state they lead to, with cards revealed in place. Every move is
+// checked against the rules, so the result is a game that could have been
+// played. A move whose team isn't the one in turn is rejected.
+func Replay(firstTeam model.Team, cards []model.Card, moves []model.Move) (model.Game, error) {
+	if firstTeam != model.TeamRed && firstTeam != model.TeamBlue {
+		return model.Game{}, fmt.Errorf("invalid first team %q", firstTeam)
+	}
+	game := model.Game{Phase: model.PhasePlaying, CurrentTeam: firstTeam}
+	for i, m := range moves {
+		if err := replayMove(&game, cards, m); err != nil {
+			return model.Game{}, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		game.Version++
+	}
+	return game, nil
+}
+
+func replayMove(game *model.Game, cards []model.Card, m model.Move) error {
+	if game.Phase != model.PhasePlaying {
+		return errors.New("game is already finished")
+	}
+	if m.Team != game.CurrentTeam {
+		return fmt.Errorf("not %s's turn", m.Team)
+	}
+	switch m.Kind {
+	case model.MoveClue:
+		return giveClue(game, m.Clue, m.Number)
+	case model.MoveGuess:
+		i := slices.IndexFunc(cards, func(c model.Card) bool { return c.Position == m.Position })
+		if i < 0 {
+			return fmt.Errorf("no card at position %d", m.Position)
+		}
+		return guessCard(game, cards, i, m.Team)
+	case model.MoveEndGuessing:
+		return endGuessing(game)
+	case model.MoveFinish:
+		if m.Winner != "" && m.Winner != model.TeamRed && m.Winner != model.TeamBlue {
+			return fmt.Errorf("invalid winner %q", m.Winner)
+		}
+		return forceFinish(game, m.Winner)
+	}
+	return fmt.Errorf("unknown move %q", m.Kind)
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"codenames/internal/model"
+)
+
+// testBoard returns a 16 card board: red at 0-3, blue at 4-6, the assassin
+// at 7 and neutral cards after.
+func testBoard() []model.Card {
+	cards := make([]model.Card, 16)
+	for i := range cards {
+		cards[i] = model.Card{Position: i, Word: "W" + string(rune('A'+i)), CardType: model.CardTypeNeutral}
+		switch {
+		case i < 4:
+			cards[i].CardType = model.CardTypeRed
+		case i < 7:
+			cards[i].CardType = model.CardTypeBlue
+		case i == 7:
+			cards[i].CardType = model.CardTypeAssassin
+		}
+	}
+	return cards
+}
+
+func clue(team model.Team, number int) model.Move {
+	return model.Move{Kind: model.MoveClue, Team: team, Clue: "ШПИОН", Number: number}
+}
+
+func guess(team model.Team, position int) model.Move {
+	return model.Move{Kind: model.MoveGuess, Team: team, Position: position}
+}
+
+func TestReplay(t *testing.T) {
+	red, blue := model.TeamRed, model.TeamBlue
+	tests := []struct {
+		name      string
+		firstTeam model.Team
+		moves     []model.Move
+		want      model.Game
+		revealed  []int
+		wantErr   string
+	}{
+		{
+			name:      "no moves",
+			firstTeam: blue,
+			want:      model.Game{Phase: model.PhasePlaying, CurrentTeam: blue},
+		},
+		{
+			name:      "correct guess keeps the turn",
+			firstTeam: red,
+			moves:     []model.Move{clue(red, 2), guess(red, 0)},
+			want:      model.Game{Phase: model.PhasePlaying, CurrentTeam: red, CurrentClue: "ШПИОН", CurrentNumber: 2, GuessesLeft: 2},
+			revealed:  []int{0},
+		},
+		{
+			name:      "wrong guess ends the turn",
+			firstTeam: red,
+			moves:     []model.Move{clue(red, 2), guess(red, 8)},
+			want:      model.Game{Phase: model.PhasePlaying, CurrentTeam: blue},
+			revealed:  []int{8},
+		},
+		{
+			name:      "guesses run out",
+			firstTeam: red,
+			moves:     []model.Move{clue(red, 1), guess(red, 0), guess(red, 1)},
+			want:      model.Game{Phase: model.PhasePlaying, CurrentTeam: blue},
+			revealed:  []int{0, 1},
+		},
+		{
+			name:      "end guessing",
+			firstTeam: blue,
+			moves:     []model.Move{clue(blue, 3), guess(blue, 4), {Kind: model.MoveEndGuessing, Team: blue}},
+			want:      model.Game{Phase: model.PhasePlaying, CurrentTeam: red},
+			revealed:  []int{4},
+		},
+		{
+			name:      "all cards revealed",
+			firstTeam: blue,
+			moves:     []model.Move{clue(blue, 0), guess(blue, 4), guess(blue, 5), guess(blue, 6)},
+			want:      model.Game{Phase: model.PhaseFinished, CurrentTeam: blue, CurrentClue: "ШПИОН", GuessesLeft: 23, Winner: blue},
+			revealed:  []int{4, 5, 6},
+		},
+		{
+			name:      "revealing the other team's last card",
+			firstTeam: red,
+			moves: []model.Move{
+				clue(red, 1), guess(red, 4),
+				clue(blue, 1), guess(blue, 8),
+				clue(red, 1), guess(red, 5),
+				clue(blue, 1), guess(blue, 9),
+				clue(red, 1), guess(red, 6),
+			},
+			want:     model.Game{Phase: model.PhaseFinished, CurrentTeam: red, CurrentClue: "ШПИОН", CurrentNumber: 1, GuessesLeft: 2, Winner: blue},
+			revealed: []int{4, 5, 6, 8, 9},
+		},
+		{
+			name:      "assassin",
+			firstTeam: red,
+			moves:     []model.Move{clue(red, 1), guess(red, 7)},
+			want:      model.Game{Phase: model.PhaseFinished, CurrentTeam: red, CurrentClue: "ШПИОН", CurrentNumber: 1, GuessesLeft: 2, Winner: blue},
+			revealed:  []int{7},
+		},
+		{
+			name:      "finished by an operator",
+			firstTeam: red,
+			moves:     []model.Move{clue(red, 1), {Kind: model.MoveFinish, Team: red, Winner: red}},
+			want:      model.Game{Phase: model.PhaseFinished, CurrentTeam: red, CurrentClue: "ШПИОН", CurrentNumber: 1, GuessesLeft: 2, Winner: red},
+		},
+		{
+			name:      "finished without a winner",
+			firstTeam: red,
+			moves:     []model.Move{{Kind: model.MoveFinish, Team: red}},
+			want:      model.Game{Phase: model.PhaseFinished, CurrentTeam: red},
+		},
+		{name: "invalid first team", firstTeam: "green", wantErr: `invalid first team "green"`},
+		{name: "wrong team", firstTeam: red, moves: []model.Move{clue(blue, 1)}, wantErr: "move 1: not blue's turn"},
+		{name: "guess before a clue", firstTeam: red, moves: []model.Move{guess(red, 0)}, wantErr: "move 1: no clue given yet"},
+		{name: "second clue", firstTeam: red, moves: []model.Move{clue(red, 1), clue(red, 1)}, wantErr: "move 2: already gave a clue"},
+		{name: "negative number", firstTeam: red, moves: []model.Move{clue(red, -1)}, wantErr: "move 1: number must be >= 0"},
+		{name: "card guessed twice", firstTeam: red, moves: []model.Move{clue(red, 2), guess(red, 0), guess(red, 0)}, wantErr: "move 3: card already revealed"},
+		{name: "no card at position", firstTeam: red, moves: []model.Move{clue(red, 2), guess(red, 16)}, wantErr: "move 2: no card at position 16"},
+		{name: "move after the end", firstTeam: red, moves: []model.Move{clue(red, 1), guess(red, 7), clue(blue, 1)}, wantErr: "move 3: game is already finished"},
+		{name: "invalid winner", firstTeam: red, moves: []model.Move{{Kind: model.MoveFinish, Team: red, Winner: "green"}}, wantErr: `move 1: invalid winner "green"`},
+		{name: "unknown move", firstTeam: red, moves: []model.Move{{Kind: "pass", Team: red}}, wantErr: `move 1: unknown move "pass"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards := testBoard()
+			got, err := Replay(tt.firstTeam, cards, tt.moves)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Replay err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Replay: %v", err)
+			}
+			tt.want.Version = len(tt.moves)
+			if got != tt.want {
+				t.Errorf("Replay = %+v, want %+v", got, tt.want)
+			}
+			revealed := make(map[int]bool)
+			for _, p := range tt.revealed {
+				revealed[p] = true
+			}
+			for _, c := range cards {
+				if c.Revealed != revealed[c.Position] {
+					t.Errorf("card %d revealed = %v, want %v", c.Position, c.Revealed, revealed[c.Position])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"codenames/internal/model"
+)
+
+const (
+	minBoardSize = 16
+	maxBoardSize = 36
+	maxTimer     = 600
+)
+
+// ValidateSettings checks that the settings describe a playable game.
+// It is the single place room settings are validated before being stored.
+func ValidateSettings(s model.RoomSettings) error {
+	if s.Variant != model.VariantClassic {
+		return fmt.Errorf("unknown variant %q", s.Variant)
+	}
+
+	b := s.Board
+	if b.Size < minBoardSize || b.Size > maxBoardSize {
+		return fmt.Errorf("board size must be between %d and %d", minBoardSize, maxBoardSize)
+	}
+	if b.SecondTeamCards < 1 {
+		return errors.New("each team needs at least 1 card")
+	}
+	if b.FirstTeamCards < b.SecondTeamCards {
+		return errors.New("first team cannot have fewer cards than second team")
+	}
+	if b.Assassins < 0 {
+		return errors.New("assassins must be >= 0")
+	}
+	if b.NeutralCards() < 0 {
+		return errors.New("too many team and assassin cards for board size")
+	}
+
+	if s.Timers.ClueSeconds < 0 || s.Timers.ClueSeconds > maxTimer {
+		return fmt.Errorf("clue timer must be between 0 and %d seconds", maxTimer)
+	}
+	if s.Timers.GuessSeconds < 0 || s.Timers.GuessSeconds > maxTimer {
+		return fmt.Errorf("guess timer must be between 0 and %d seconds", maxTimer)
+	}
+
+	if len(s.WordPacks) == 0 {
+		return errors.New("at least 1 word pack is required")
+	}
+	for _, name := range s.WordPacks {
+		pack, ok := WordPacks[name]
+		if !ok {
+			return fmt.Errorf("unknown word pack %q", name)
+		}
+		if pack.Language != s.Language {
+			return fmt.Errorf("word pack %q is not in language %q", name, s.Language)
+		}
+	}
+	if len(wordPool(s.WordPacks)) < b.Size {
+		return errors.New("not enough words in selected packs for board size")
+	}
+
+	if !validPermission(s.Permissions.EditSettings) || !validPermission(s.Permissions.StartGame) {
+		return errors.New("invalid permission")
+	}
+	return nil
+}
+
+func validPermission(p model.Permission) bool {
+	return p == model.PermissionAnyone || p == model.PermissionSpymasters
+}
@@ -1,5 +1,24 @@
 package game
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+// WordPack is a named list of words in a single language.
+type WordPack = model.WordPack
+
+// WordPacks lists the packs a room can draw its board from: the built-in
+// ones, then the ones imported into the database once LoadWordPacks ran.
+var WordPacks = map[string]WordPack{
+	"ru-classic": {Language: "ru", Words: RussianWords},
+}
+
 var RussianWords = []string{
 	"АГЕНТ", "АЗИЯ", "АКУЛА", "АЛМАЗ", "АЛЬПЫ", "АМЕРИКА", "АНГЕЛ", "АНТАРКТИДА",
 	"АППАРАТ", "АТЛАС", "АФРИКА", "БАНК", "БАРОН", "БАССЕЙН", "БАТАРЕЯ", "БАШНЯ",
@@ -47,3 +66,61 @@ var RussianWords = []string{
 	"ШТОРА", "ЩЕНОК", "ЩЁТКА", "ЩУКА", "ЭКРАН", "ЭЛЬФ", "ЭСКИМО", "ЭФИР",
 	"ЮПИТЕР", "ЯБЛОКО", "ЯКОРЬ", "ЯЙЦО", "ЯЩЕРИЦА",
 }
+
+// builtinWordPacks are the names of the packs shipped with the server.
+var builtinWordPacks = func() map[string]bool {
+	names := make(map[string]bool, len(WordPacks))
+	for name := range WordPacks {
+		names[name] = true
+	}
+	return names
+}()
+
+// IsBuiltinWordPack reports whether the name belongs to a built-in pack,
+// which imported packs cannot replace.
+func IsBuiltinWordPack(name string) bool {
+	return builtinWordPacks[name]
+}
+
+// ValidateWordPack checks a pack before it is imported: it needs a name
+// that isn't a built-in pack's, a language, and enough distinct words to
+// fill the smallest board.
+func ValidateWordPack(name string, pack WordPack) error {
+	if name == "" {
+		return errors.New("word pack name is required")
+	}
+	if IsBuiltinWordPack(name) {
+		return fmt.Errorf("word pack %q is built in", name)
+	}
+	if pack.Language == "" {
+		return errors.New("word pack language is required")
+	}
+	seen := make(map[string]bool, len(pack.Words))
+	for _, w := range pack.Words {
+		if w == "" {
+			return errors.New("word pack has an empty word")
+		}
+		seen[w] = true
+	}
+	if len(seen) < minBoardSize {
+		return fmt.Errorf("word pack has %d distinct words, want at least %d", len(seen), minBoardSize)
+	}
+	return nil
+}
+
+// LoadWordPacks adds the packs imported into the store to WordPacks. It must
+// run before any game starts.
+func LoadWordPacks(ctx context.Context, store storage.WordPackStore) error {
+	packs, err := store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("load word packs: %w", err)
+	}
+	for name, pack := range packs {
+		if IsBuiltinWordPack(name) {
+			slog.Warn("imported word pack shadows a built-in one, ignoring it", "name", name)
+			continue
+		}
+		WordPacks[name] = pack
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"codenames/internal/auth"
+	"codenames/internal/game"
+	"codenames/internal/hub"
+	"codenames/internal/model"
+	"codenames/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const maxAnnouncementLength = 500
+
+// AdminHandler serves the operators' API. Requests must carry the admin
+// token as a bearer token; without a configured token the API is disabled.
+type AdminHandler struct {
+	hub        *hub.Hub
+	roomRepo   storage.RoomStore
+	playerRepo storage.PlayerStore
+	gameRepo   storage.GameStore
+	token      []byte
+}
+
+func NewAdminHandler(h *hub.Hub, roomRepo storage.RoomStore, playerRepo storage.PlayerStore, gameRepo storage.GameStore, token []byte) *AdminHandler {
+	return &AdminHandler{hub: h, roomRepo: roomRepo, playerRepo: playerRepo, gameRepo: gameRepo, token: token}
+}
+
+// Authorize rejects requests without the admin token.
+func (h *AdminHandler) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(h.token) == 0 {
+			http.Error(w, "admin API is disabled", http.StatusForbidden)
+			return
+		}
+		token := []byte(auth.TokenFromRequest(r))
+		if subtle.ConstantTimeCompare(token, h.token) != 1 {
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *AdminHandler) ListRooms(w http.ResponseWriter, r *http.Request) {
+	rooms, err := h.roomRepo.List(r.Context())
+	if err != nil {
+		http.Error(w, "failed to list rooms", http.StatusInternalServerError)
+		return
+	}
+	if rooms == nil {
+		rooms = []model.RoomSummary{}
+	}
+	writeJSON(w, http.StatusOK, rooms)
+}
+
+// adminRoomState is a room as seen by operators: every card type is shown.
+type adminRoomState struct {
+	model.RoomState
+	// Connections counts the clients connected to this instance.
+	Connections int `json:"connections"`
+}
+
+func (h *AdminHandler) GetRoom(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	room, err := h.roomRepo.GetByID(r.Context(), id)
+	if err != nil {
+		adminError(w, err, "failed to get room")
+		return
+	}
+	players, err := h.playerRepo.GetByRoomID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "failed to get players", http.StatusInternalServerError)
+		return
+	}
+	if players == nil {
+		players = []model.Player{}
+	}
+
+	state := adminRoomState{RoomState: model.RoomState{Room: room, Players: players}, Connections: h.hub.Connections(id)}
+	g, err := h.gameRepo.GetActiveByRoomID(r.Context(), id)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "failed to get game", http.StatusInternalServerError)
+		return
+	}
+	if err == nil {
+		cards, err := h.gameRepo.GetCardsByGameID(r.Context(), g.ID)
+		if err != nil {
+			http.Error(w, "failed to get cards", http.StatusInternalServerError)
+			return
+		}
+		state.Game = &g
+		for _, c := range cards {
+			state.Cards = append(state.Cards, model.CardToView(c, true))
+			if c.Revealed {
+				continue
+			}
+			switch c.CardType {
+			case model.CardTypeRed:
+				state.RedCardsLeft++
+			case model.CardTypeBlue:
+				state.BlueCardsLeft++
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+type finishGameReq struct {
+	// Winner is red, blue or empty for no winner.
+	Winner model.Team `json:"winner"`
+}
+
+// FinishGame ends the room's game. The body is optional.
+func (h *AdminHandler) FinishGame(w http.ResponseWriter, r *http.Request) {
+	var req finishGameReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength != 0 {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Winner != "" && req.Winner != model.TeamRed && req.Winner != model.TeamBlue {
+		http.Error(w, "winner must be red, blue or empty", http.StatusBadRequest)
+		return
+	}
+	if err := h.hub.FinishGame(r.Context(), chi.URLParam(r, "id"), req.Winner); err != nil {
+		adminError(w, err, "failed to finish game")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) ResetGame(w http.ResponseWriter, r *http.Request) {
+	if err := h.hub.ResetGame(r.Context(), chi.URLParam(r, "id")); err != nil {
+		adminError(w, err, "failed to reset game")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) KickPlayer(w http.ResponseWriter, r *http.Request) {
+	if err := h.hub.Kick(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "playerID")); err != nil {
+		adminError(w, err, "failed to kick player")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.hub.DeleteRoom(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		adminError(w, err, "failed to delete room")
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+type announceReq struct {
+	Message string `json:"message"`
+	// RoomIDs are the rooms to announce to, every room with online
+	// players when empty.
+	RoomIDs []string `json:"room_ids"`
+}
+
+// announceResp lists the rooms the message reached, and why it didn't reach
+// the others.
+type announceResp struct {
+	Rooms   int               `json:"rooms"`
+	Reached []string          `json:"reached"`
+	Failed  map[string]string `json:"failed,omitempty"`
+}
+
+func (h *AdminHandler) Announce(w http.ResponseWriter, r *http.Request) {
+	var req announceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Message == "" || len(req.Message) > maxAnnouncementLength {
+		http.Error(w, "message must be 1 to 500 bytes", http.StatusBadRequest)
+		return
+	}
+
+	roomIDs := req.RoomIDs
+	// Named rooms must all exist, so a typo doesn't leave the message sent
+	// to only some of them.
+	for _, id := range roomIDs {
+		if _, err := h.roomRepo.GetByID(r.Context(), id); err != nil {
+			adminError(w, err, "failed to get room")
+			return
+		}
+	}
+	if len(roomIDs) == 0 {
+		rooms, err := h.roomRepo.List(r.Context())
+		if err != nil {
+			http.Error(w, "failed to list rooms", http.StatusInternalServerError)
+			return
+		}
+		for _, room := range rooms {
+			if room.OnlinePlayers > 0 {
+				roomIDs = append(roomIDs, room.ID)
+			}
+		}
+	}
+	// One failing room doesn't stop the rest from getting the message.
+	resp := announceResp{Reached: []string{}}
+	var firstErr error
+	for _, id := range roomIDs {
+		if err := h.hub.Announce(r.Context(), id, req.Message); err != nil {
+			if resp.Failed == nil {
+				resp.Failed = map[string]string{}
+				firstErr = err
+			}
+			resp.Failed[id] = err.Error()
+			continue
+		}
+		resp.Reached = append(resp.Reached, id)
+	}
+	resp.Rooms = len(resp.Reached)
+	if resp.Rooms == 0 && firstErr != nil {
+		adminError(w, firstErr, "failed to announce")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// adminError answers with the status matching err.
+func adminError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, game.ErrNotPlaying), errors.Is(err, storage.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, hub.ErrStopped):
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+	default:
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"unicode/utf8"
+
+	"codenames/internal/auth"
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+const minPasswordLength = 8
+
+// maxPasswordBytes is the most bcrypt hashes; it rejects longer passwords.
+const maxPasswordBytes = 72
+
+// dummyPasswordHash is checked against when logging in as a user that
+// doesn't exist, so the response takes as long as for a wrong password and
+// doesn't tell which usernames are taken.
+const dummyPasswordHash = "$2a$10$xwpC6B0CEBM1FQtd1QoO0uTpsJKbHRcwWMrpyPFwkslx0M83PzuWe"
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,32}$`)
+
+type AuthHandler struct {
+	userRepo storage.UserStore
+	signer   *auth.Signer
+}
+
+func NewAuthHandler(userRepo storage.UserStore, signer *auth.Signer) *AuthHandler {
+	return &AuthHandler{userRepo: userRepo, signer: signer}
+}
+
+type credentialsReq struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type authResp struct {
+	Token string      `json:"token"`
+	User  *model.User `json:"user,omitempty"`
+}
+
+// Guest starts a new anonymous session.
+func (h *AuthHandler) Guest(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := auth.NewSessionID()
+	if err != nil {
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+	token, err := h.signer.Issue(sessionID, "")
+	if err != nil {
+		http.Error(w, "failed to issue token", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, authResp{Token: token})
+}
+
+// Register creates an account. When called with a valid guest token the
+// account takes over the guest session, keeping its players and ratings.
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req credentialsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !usernamePattern.MatchString(req.Username) {
+		http.Error(w, "username must be 3-32 letters, digits, '_' or '-'", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.Password) < minPasswordLength {
+		http.Error(w, "password must be at least 8 characters", http.StatusBadRequest)
+		return
+	}
+	if len(req.Password) > maxPasswordBytes {
+		http.Error(w, "password must be at most 72 bytes", http.StatusBadRequest)
+		return
+	}
+
+	var sessionID string
+	if token := auth.TokenFromRequest(r); token != "" {
+		claims, err := h.signer.Verify(token)
+		if err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		if !claims.IsGuest() {
+			http.Error(w, "already registered", http.StatusBadRequest)
+			return
+		}
+		sessionID = claims.SessionID
+	} else {
+		var err error
+		if sessionID, err = auth.NewSessionID(); err != nil {
+			http.Error(w, "failed to create session", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		http.Error(w, "failed to create account", http.StatusInternalServerError)
+		return
+	}
+	user, err := h.userRepo.Create(r.Context(), req.Username, hash, sessionID)
+	switch {
+	case errors.Is(err, storage.ErrUsernameTaken):
+		http.Error(w, "username already taken", http.StatusConflict)
+		return
+	case errors.Is(err, storage.ErrSessionTaken):
+		http.Error(w, "session already belongs to an account", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "failed to create account", http.StatusInternalServerError)
+		return
+	}
+	h.writeToken(w, http.StatusCreated, user)
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req credentialsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	user, err := h.userRepo.GetByUsername(r.Context(), req.Username)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "failed to log in", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		auth.CheckPassword(dummyPasswordHash, req.Password)
+		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	h.writeToken(w, http.StatusOK, user)
+}
+
+func (h *AuthHandler) writeToken(w http.ResponseWriter, status int, user model.User) {
+	token, err := h.signer.Issue(user.SessionID, user.ID)
+	if err != nil {
+		http.Error(w, "failed to issue token", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, authResp{Token: token, User: &user})
+}
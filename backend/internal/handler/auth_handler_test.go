@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"codenames/internal/auth"
+	"codenames/internal/storage/memory"
+)
+
+func TestRegisterPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		wantStatus int
+	}{
+		{"too short", "short", http.StatusBadRequest},
+		{"8 bytes but 4 characters", "пароль"[:8], http.StatusBadRequest},
+		{"8 characters", "парольпа", http.StatusCreated},
+		{"72 bytes", strings.Repeat("п", 36), http.StatusCreated},
+		{"over 72 bytes", strings.Repeat("п", 37), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewAuthHandler(memory.NewStore().Users, auth.NewSigner([]byte("secret"), time.Hour))
+			body := `{"username": "anya", "password": "` + tt.password + `"}`
+			rec := httptest.NewRecorder()
+			h.Register(rec, httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
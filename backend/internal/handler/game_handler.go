@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"codenames/internal/auth"
+	"codenames/internal/hub"
+	"codenames/internal/logging"
+	"codenames/internal/model"
+	"codenames/internal/record"
+	"codenames/internal/render"
+	"codenames/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxRecordSize is the largest game record accepted for import, in bytes.
+const maxRecordSize = 1 << 20
+
+// GameHandler exports finished games as records and imports them into
+// rooms, see package record, and renders games to print.
+type GameHandler struct {
+	hub        *hub.Hub
+	roomRepo   storage.RoomStore
+	playerRepo storage.PlayerStore
+	gameRepo   storage.GameStore
+	signer     *auth.Signer
+}
+
+func NewGameHandler(h *hub.Hub, roomRepo storage.RoomStore, playerRepo storage.PlayerStore, gameRepo storage.GameStore, signer *auth.Signer) *GameHandler {
+	return &GameHandler{hub: h, roomRepo: roomRepo, playerRepo: playerRepo, gameRepo: gameRepo, signer: signer}
+}
+
+// Export serves the record of a finished game as a download to players of
+// its room. The key is left out unless the room reveals it once a game is
+// over.
+func (h *GameHandler) Export(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	claims, err := h.signer.Verify(auth.TokenFromRequest(r))
+	if err != nil {
+		http.Error(w, "valid token required", http.StatusUnauthorized)
+		return
+	}
+	g, err := h.gameRepo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "game not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get game", http.StatusInternalServerError)
+		return
+	}
+	room, err := h.roomRepo.GetByID(r.Context(), g.RoomID)
+	if err != nil {
+		http.Error(w, "failed to get room", http.StatusInternalServerError)
+		return
+	}
+	if _, err := h.playerRepo.GetBySessionAndRoom(r.Context(), claims.SessionID, g.RoomID); err != nil {
+		http.Error(w, "only players of this room may export its games", http.StatusForbidden)
+		return
+	}
+	cards, err := h.gameRepo.GetCardsByGameID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "failed to get cards", http.StatusInternalServerError)
+		return
+	}
+	history, err := h.gameRepo.GetHistory(r.Context(), id)
+	if err != nil {
+		http.Error(w, "failed to get game history", http.StatusInternalServerError)
+		return
+	}
+
+	rec, err := record.New(g, cards, history, time.Now())
+	switch {
+	case errors.Is(err, record.ErrInProgress):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, record.ErrNoHistory):
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	case err != nil:
+		logging.FromContext(r.Context()).Error("export game", "game_id", id, "err", err)
+		http.Error(w, "failed to export game", http.StatusInternalServerError)
+		return
+	}
+	if g.Phase != model.PhaseFinished || !room.Settings.Visibility.RevealKeyOnFinish {
+		rec.Redact()
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="codenames-%s.json"`, g.ID))
+	writeJSON(w, http.StatusOK, rec)
+}
+
+// Import adds the game of a record to a room. Only a player of the room
+// allowed to start games may import one, and not while a game is played.
+func (h *GameHandler) Import(w http.ResponseWriter, r *http.Request) {
+	roomID := chi.URLParam(r, "id")
+	claims, err := h.signer.Verify(auth.TokenFromRequest(r))
+	if err != nil {
+		http.Error(w, "valid token required", http.StatusUnauthorized)
+		return
+	}
+	room, err := h.roomRepo.GetByID(r.Context(), roomID)
+	if err != nil {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	player, err := h.playerRepo.GetBySessionAndRoom(r.Context(), claims.SessionID, roomID)
+	if err != nil {
+		http.Error(w, "player not found in this room", http.StatusNotFound)
+		return
+	}
+	if !room.Settings.Permissions.StartGame.Allows(player.Role) {
+		http.Error(w, "not allowed to start games in this room", http.StatusForbidden)
+		return
+	}
+
+	rec, err := record.Decode(http.MaxBytesReader(w, r.Body, maxRecordSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	g, cards, history, err := rec.Game()
+	if err != nil {
+		http.Error(w, "invalid record: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	imported, err := h.hub.ImportGame(r.Context(), roomID, g, cards, history)
+	switch {
+	case errors.Is(err, hub.ErrGameInProgress):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, hub.ErrStopped):
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		logging.FromContext(r.Context()).Error("import game", "room_id", roomID, "err", err)
+		http.Error(w, "failed to import game", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"game_id": imported.ID})
+}
+
+// Render serves a sheet of a room's current game to print, as SVG or PDF
+// by the extension, with the patterns query parameter set to true for
+// colour-blind patterns. Both sheets give the key away, so only the
+// spymasters the game was started with may get them.
+func (h *GameHandler) Render(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sheet, err := render.ParseSheet(chi.URLParam(r, "sheet"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	format := chi.URLParam(r, "format")
+	if format != "svg" && format != "pdf" {
+		http.Error(w, "unknown format, want svg or pdf", http.StatusNotFound)
+		return
+	}
+	claims, err := h.signer.Verify(auth.TokenFromRequest(r))
+	if err != nil {
+		http.Error(w, "valid token required", http.StatusUnauthorized)
+		return
+	}
+
+	g, err := h.gameRepo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "game not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get game", http.StatusInternalServerError)
+		return
+	}
+	history, err := h.gameRepo.GetHistory(r.Context(), id)
+	if err != nil {
+		http.Error(w, "failed to get game history", http.StatusInternalServerError)
+		return
+	}
+	player, err := h.playerRepo.GetBySessionAndRoom(r.Context(), claims.SessionID, g.RoomID)
+	if err != nil || !spymasterOf(history, player.ID) {
+		http.Error(w, "only spymasters of this game may print it", http.StatusForbidden)
+		return
+	}
+	if active, err := h.gameRepo.GetActiveByRoomID(r.Context(), g.RoomID); err != nil || active.ID != g.ID {
+		http.Error(w, "only spymasters of this game may print it", http.StatusForbidden)
+		return
+	}
+	cards, err := h.gameRepo.GetCardsByGameID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "failed to get cards", http.StatusInternalServerError)
+		return
+	}
+
+	opts := render.Options{Sheet: sheet, Patterns: r.URL.Query().Get("patterns") == "true"}
+	var buf bytes.Buffer
+	contentType := "image/svg+xml"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = render.PDF(&buf, cards, history.FirstTeam, opts)
+	} else {
+		err = render.SVG(&buf, cards, history.FirstTeam, opts)
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("render game", "game_id", id, "err", err)
+		http.Error(w, "failed to render game", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="codenames-%s-%s.%s"`, g.ID, sheet, format))
+	w.Write(buf.Bytes())
+}
+
+// spymasterOf reports whether a player is one of the spymasters a game was
+// started with, whatever their name or seat is now.
+func spymasterOf(history model.GameHistory, playerID string) bool {
+	return slices.ContainsFunc(history.Players, func(gp model.GamePlayer) bool {
+		return gp.PlayerID == playerID && gp.Role == model.RoleSpymaster
+	})
+}
+
+// RecordSchema serves the JSON Schema of game records.
+func RecordSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, json.RawMessage(record.Schema()))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"codenames/internal/auth"
+	"codenames/internal/model"
+	"codenames/internal/storage/memory"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestRenderKeyAfterRename checks that the key card goes to the spymaster a
+// game was started with, not to whoever takes their name and seat once the
+// game is over.
+func TestRenderKeyAfterRename(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	signer := auth.NewSigner([]byte("secret"), time.Hour)
+
+	room, err := store.Rooms.Create(ctx)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	spymaster, err := store.Players.Upsert(ctx, room.ID, "s1", "Аня")
+	if err != nil {
+		t.Fatalf("add spymaster: %v", err)
+	}
+	operative, err := store.Players.Upsert(ctx, room.ID, "s2", "Боря")
+	if err != nil {
+		t.Fatalf("add operative: %v", err)
+	}
+	cards := make([]model.Card, 25)
+	for i := range cards {
+		cards[i] = model.Card{Position: i, Word: "СЛОВО", CardType: model.CardTypeNeutral}
+	}
+	cards[0].CardType, cards[1].CardType = model.CardTypeRed, model.CardTypeBlue
+	roster := []model.GamePlayer{
+		{PlayerID: spymaster.ID, Name: "Аня", Team: model.TeamRed, Role: model.RoleSpymaster},
+		{PlayerID: operative.ID, Name: "Боря", Team: model.TeamRed, Role: model.RoleOperative},
+	}
+	g, _, err := store.Games.CreateWithCards(ctx, room.ID, model.TeamRed, cards, roster)
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	g.Phase = model.PhaseFinished
+	if _, err := store.Games.Save(ctx, g, model.Move{Kind: model.MoveFinish, Team: model.TeamRed}, nil); err != nil {
+		t.Fatalf("finish game: %v", err)
+	}
+
+	// Once the game is over the operative takes the spymaster's name and seat.
+	if _, err := store.Players.Upsert(ctx, room.ID, "s2", "Аня"); err != nil {
+		t.Fatalf("rename operative: %v", err)
+	}
+	if err := store.Players.SetTeamRole(ctx, operative.ID, model.TeamRed, model.RoleSpymaster); err != nil {
+		t.Fatalf("move operative: %v", err)
+	}
+
+	h := NewGameHandler(nil, store.Rooms, store.Players, store.Games, signer)
+	r := chi.NewRouter()
+	r.Get("/api/games/{id}/{sheet}.{format}", h.Render)
+
+	tests := []struct {
+		name       string
+		sessionID  string
+		wantStatus int
+	}{
+		{"recorded spymaster", "s1", http.StatusOK},
+		{"renamed operative", "s2", http.StatusForbidden},
+		{"not in the room", "s3", http.StatusForbidden},
+		{"no token", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/games/"+g.ID+"/key.svg", nil)
+			if tt.sessionID != "" {
+				token, err := signer.Issue(tt.sessionID, "")
+				if err != nil {
+					t.Fatalf("issue token: %v", err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
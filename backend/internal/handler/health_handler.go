@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck is a dependency the server needs to serve traffic.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthHandler serves liveness and readiness probes. Once draining, the
+// server reports itself not ready and refuses new WebSocket connections,
+// so load balancers send new clients elsewhere while it shuts down.
+type HealthHandler struct {
+	checks   []HealthCheck
+	draining atomic.Bool
+}
+
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// Drain marks the server as shutting down.
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+func (h *HealthHandler) Draining() bool {
+	return h.draining.Load()
+}
+
+type readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Live reports that the process is up.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, readiness{Status: "ok"})
+}
+
+// Ready runs every check and reports 503 if one fails or the server is
+// draining.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.Draining() {
+		writeJSON(w, http.StatusServiceUnavailable, readiness{Status: "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+	res := readiness{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+	status := http.StatusOK
+	for _, c := range h.checks {
+		if err := c.Check(ctx); err != nil {
+			res.Checks[c.Name] = err.Error()
+			res.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		res.Checks[c.Name] = "ok"
+	}
+	writeJSON(w, status, res)
+}
+
+// RejectWhileDraining answers 503 to requests that arrive while draining.
+func (h *HealthHandler) RejectWhileDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.Draining() {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 100
+)
+
+type LeaderboardHandler struct {
+	ratingRepo storage.RatingStore
+}
+
+func NewLeaderboardHandler(ratingRepo storage.RatingStore) *LeaderboardHandler {
+	return &LeaderboardHandler{ratingRepo: ratingRepo}
+}
+
+func (h *LeaderboardHandler) AllTime(w http.ResponseWriter, r *http.Request) {
+	role, limit, ok := leaderboardParams(w, r)
+	if !ok {
+		return
+	}
+	ratings, err := h.ratingRepo.Leaderboard(r.Context(), role, limit)
+	if err != nil {
+		http.Error(w, "failed to get leaderboard", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, ratings)
+}
+
+func (h *LeaderboardHandler) Room(w http.ResponseWriter, r *http.Request) {
+	role, limit, ok := leaderboardParams(w, r)
+	if !ok {
+		return
+	}
+	ratings, err := h.ratingRepo.RoomLeaderboard(r.Context(), chi.URLParam(r, "id"), role, limit)
+	if err != nil {
+		http.Error(w, "failed to get leaderboard", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, ratings)
+}
+
+// leaderboardParams reads the role (operative by default) and limit query params.
+func leaderboardParams(w http.ResponseWriter, r *http.Request) (model.Role, int, bool) {
+	role := model.Role(r.URL.Query().Get("role"))
+	if role == "" {
+		role = model.RoleOperative
+	}
+	if role != model.RoleSpymaster && role != model.RoleOperative {
+		http.Error(w, "role must be spymaster or operative", http.StatusBadRequest)
+		return "", 0, false
+	}
+
+	limit := defaultLeaderboardLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return "", 0, false
+		}
+		limit = min(n, maxLeaderboardLimit)
+	}
+	return role, limit, true
+}
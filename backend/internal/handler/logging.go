@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"codenames/internal/logging"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// requestLogger puts a logger carrying the request ID set by
+// middleware.RequestID into the request context, returns the ID in the
+// X-Request-Id header and logs the request once it is done.
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		reqID := middleware.GetReqID(r.Context())
+		w.Header().Set(middleware.RequestIDHeader, reqID)
+		log := slog.With("request_id", reqID)
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(logging.NewContext(r.Context(), log)))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		log.Log(r.Context(), level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr,
+		)
+	})
+}
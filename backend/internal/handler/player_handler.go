@@ -4,15 +4,17 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"codenames/internal/auth"
 	"codenames/internal/storage"
 )
 
 type PlayerHandler struct {
-	playerRepo *storage.PlayerRepo
+	playerRepo storage.PlayerStore
+	signer     *auth.Signer
 }
 
-func NewPlayerHandler(playerRepo *storage.PlayerRepo) *PlayerHandler {
-	return &PlayerHandler{playerRepo: playerRepo}
+func NewPlayerHandler(playerRepo storage.PlayerStore, signer *auth.Signer) *PlayerHandler {
+	return &PlayerHandler{playerRepo: playerRepo, signer: signer}
 }
 
 type createPlayerReq struct {
@@ -21,9 +23,9 @@ type createPlayerReq struct {
 }
 
 func (h *PlayerHandler) Create(w http.ResponseWriter, r *http.Request) {
-	sessionID := r.Header.Get("X-Session-ID")
-	if sessionID == "" {
-		http.Error(w, "X-Session-ID header required", http.StatusBadRequest)
+	claims, err := h.signer.Verify(auth.TokenFromRequest(r))
+	if err != nil {
+		http.Error(w, "valid token required", http.StatusUnauthorized)
 		return
 	}
 
@@ -37,7 +39,7 @@ func (h *PlayerHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	player, err := h.playerRepo.Upsert(r.Context(), req.RoomID, sessionID, req.Name)
+	player, err := h.playerRepo.Upsert(r.Context(), req.RoomID, claims.SessionID, req.Name)
 	if err != nil {
 		http.Error(w, "failed to create player", http.StatusInternalServerError)
 		return
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"codenames/internal/protocol"
+)
+
+// ProtocolSchema serves the JSON Schema of the WebSocket protocol.
+func ProtocolSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, protocol.Schema())
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"codenames/internal/auth"
+	"codenames/internal/ratelimit"
+)
+
+// RateLimiter limits requests per client IP and, for requests carrying a
+// valid token, per session.
+type RateLimiter struct {
+	signer    *auth.Signer
+	byIP      *ratelimit.Limiter
+	bySession *ratelimit.Limiter
+}
+
+func NewRateLimiter(signer *auth.Signer, perIP, perSession ratelimit.Limit) *RateLimiter {
+	return &RateLimiter{
+		signer:    signer,
+		byIP:      ratelimit.New(perIP),
+		bySession: ratelimit.New(perSession),
+	}
+}
+
+// Limit answers 429 Too Many Requests with a Retry-After header once a
+// client is over either limit.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retry := l.byIP.Allow(clientIP(r))
+		if ok {
+			if claims, err := l.signer.Verify(auth.TokenFromRequest(r)); err == nil {
+				ok, retry = l.bySession.Allow(claims.SessionID)
+			}
+		}
+		if !ok {
+			secs := strconv.Itoa(int(math.Ceil(retry.Seconds())))
+			w.Header().Set("Retry-After", secs)
+			http.Error(w, "too many requests, try again in "+secs+"s", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the IP of the request's client, as set by
+// middleware.RealIP when the server runs behind a trusted proxy.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -11,12 +11,12 @@ import (
 )
 
 type RoomHandler struct {
-	roomRepo   *storage.RoomRepo
-	playerRepo *storage.PlayerRepo
-	gameRepo   *storage.GameRepo
+	roomRepo   storage.RoomStore
+	playerRepo storage.PlayerStore
+	gameRepo   storage.GameStore
 }
 
-func NewRoomHandler(roomRepo *storage.RoomRepo, playerRepo *storage.PlayerRepo, gameRepo *storage.GameRepo) *RoomHandler {
+func NewRoomHandler(roomRepo storage.RoomStore, playerRepo storage.PlayerStore, gameRepo storage.GameStore) *RoomHandler {
 	return &RoomHandler{roomRepo: roomRepo, playerRepo: playerRepo, gameRepo: gameRepo}
 }
 
@@ -6,34 +6,76 @@ import (
 	"os"
 	"strings"
 
+	"codenames/internal/metrics"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func NewRouter(roomH *RoomHandler, playerH *PlayerHandler, wsH *WSHandler) *chi.Mux {
+// NewRouter builds the routes. With trustProxy set, the client IP used for
+// logging and rate limits is read from the X-Forwarded-For and X-Real-IP
+// headers.
+func NewRouter(roomH *RoomHandler, playerH *PlayerHandler, wsH *WSHandler, leaderboardH *LeaderboardHandler, authH *AuthHandler, healthH *HealthHandler, adminH *AdminHandler, gameH *GameHandler, authLimit, roomLimit, playerLimit *RateLimiter, trustProxy bool) *chi.Mux {
 	r := chi.NewRouter()
 
-	r.Use(middleware.Logger)
+	if trustProxy {
+		r.Use(middleware.RealIP)
+	}
+	r.Use(middleware.RequestID)
+	r.Use(otelhttp.NewMiddleware("http"))
+	r.Use(nameSpan)
+	r.Use(requestLogger)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Content-Type", "X-Session-ID"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"Link", "Retry-After"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
 	// API routes
 	r.Route("/api", func(r chi.Router) {
-		r.Post("/rooms", roomH.Create)
+		r.With(authLimit.Limit).Post("/auth/guest", authH.Guest)
+		r.With(authLimit.Limit).Post("/auth/register", authH.Register)
+		r.With(authLimit.Limit).Post("/auth/login", authH.Login)
+		r.With(roomLimit.Limit).Post("/rooms", roomH.Create)
 		r.Get("/rooms/{id}", roomH.Get)
-		r.Post("/players", playerH.Create)
+		r.With(playerLimit.Limit).Post("/players", playerH.Create)
+		r.Get("/leaderboard", leaderboardH.AllTime)
+		r.Get("/rooms/{id}/leaderboard", leaderboardH.Room)
+		r.Get("/protocol/schema", ProtocolSchema)
+		r.Get("/games/schema", RecordSchema)
+		r.Get("/games/{id}/export", gameH.Export)
+		r.Get("/games/{id}/{sheet}.{format}", gameH.Render)
+		r.Post("/rooms/{id}/games", gameH.Import)
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(adminH.Authorize)
+			r.Get("/rooms", adminH.ListRooms)
+			r.Get("/rooms/{id}", adminH.GetRoom)
+			r.Delete("/rooms/{id}", adminH.DeleteRoom)
+			r.Post("/rooms/{id}/finish", adminH.FinishGame)
+			r.Post("/rooms/{id}/reset", adminH.ResetGame)
+			r.Delete("/rooms/{id}/players/{playerID}", adminH.KickPlayer)
+			r.Post("/announcements", adminH.Announce)
+		})
 	})
 
 	// WebSocket
-	r.Get("/ws/{roomID}", wsH.Handle)
+	r.With(healthH.RejectWhileDraining).Get("/ws/{roomID}", wsH.Handle)
+
+	// Prometheus metrics, not proxied to the public by nginx
+	r.Handle("/metrics", metrics.Handler())
+
+	// Liveness and readiness probes
+	r.Get("/healthz", healthH.Live)
+	r.Get("/readyz", healthH.Ready)
 
 	// Serve frontend static files (production)
 	staticDir := "./static"
@@ -44,13 +86,26 @@ func NewRouter(roomH *RoomHandler, playerH *PlayerHandler, wsH *WSHandler) *chi.
 	return r
 }
 
+// nameSpan names the request's span after the route it matched.
+func nameSpan(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if pattern := chi.RouteContext(r.Context()).RoutePattern(); pattern != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + pattern)
+			span.SetAttributes(attribute.String("http.route", pattern))
+		}
+	})
+}
+
 func fileServer(r chi.Router, staticDir string) {
 	root := http.Dir(staticDir)
 	fsHandler := http.FileServer(root)
 
 	r.Get("/*", func(w http.ResponseWriter, r *http.Request) {
 		// If it's an API or WS path, skip
-		if strings.HasPrefix(r.URL.Path, "/api") || strings.HasPrefix(r.URL.Path, "/ws") {
+		if strings.HasPrefix(r.URL.Path, "/api") || strings.HasPrefix(r.URL.Path, "/ws") || r.URL.Path == "/metrics" ||
+			r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
 			http.NotFound(w, r)
 			return
 		}
@@ -1,10 +1,14 @@
 package handler
 
 import (
-	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"codenames/internal/auth"
 	"codenames/internal/hub"
+	"codenames/internal/logging"
+	"codenames/internal/protocol"
 	"codenames/internal/storage"
 
 	"github.com/go-chi/chi/v5"
@@ -13,20 +17,22 @@ import (
 
 type WSHandler struct {
 	hub        *hub.Hub
-	playerRepo *storage.PlayerRepo
+	playerRepo storage.PlayerStore
+	signer     *auth.Signer
 }
 
-func NewWSHandler(h *hub.Hub, playerRepo *storage.PlayerRepo) *WSHandler {
-	return &WSHandler{hub: h, playerRepo: playerRepo}
+func NewWSHandler(h *hub.Hub, playerRepo storage.PlayerStore, signer *auth.Signer) *WSHandler {
+	return &WSHandler{hub: h, playerRepo: playerRepo, signer: signer}
 }
 
 func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	roomID := chi.URLParam(r, "roomID")
-	sessionID := r.URL.Query().Get("session_id")
-	if sessionID == "" {
-		http.Error(w, "session_id query param required", http.StatusBadRequest)
+	claims, err := h.signer.Verify(auth.TokenFromRequest(r))
+	if err != nil {
+		http.Error(w, "valid token required", http.StatusUnauthorized)
 		return
 	}
+	sessionID := claims.SessionID
 
 	player, err := h.playerRepo.GetBySessionAndRoom(r.Context(), sessionID, roomID)
 	if err != nil {
@@ -34,18 +40,45 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	offered := offeredSubprotocols(r)
+	version, enc, ok := protocol.Negotiate(offered)
+	if !ok {
+		http.Error(w, "unsupported protocol version, supported: "+strings.Join(protocol.Subprotocols(), ", "), http.StatusBadRequest)
+		return
+	}
+	var subprotocols []string
+	if len(offered) > 0 {
+		subprotocols = []string{protocol.Subprotocol(version, enc)}
+	}
+
 	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		Subprotocols:       subprotocols,
 		InsecureSkipVerify: true,
 	})
 	if err != nil {
-		log.Printf("ws accept: %v", err)
+		logging.FromContext(r.Context()).Warn("ws accept", "err", err)
 		return
 	}
 
-	client := hub.NewClient(conn, h.hub, roomID, sessionID, player.ID)
+	// Ignore a malformed last_seq: the client then just gets a fresh state.
+	lastSeq, _ := strconv.ParseUint(r.URL.Query().Get("last_seq"), 10, 64)
+
+	ctx := logging.With(r.Context(), "room_id", roomID, "player_id", player.ID)
+	client := hub.NewClient(ctx, conn, h.hub, roomID, sessionID, player.ID, clientIP(r), version, enc, lastSeq)
 	h.hub.Register(client)
 
-	ctx := r.Context()
 	go client.WritePump(ctx)
 	client.ReadPump(ctx)
 }
+
+func offeredSubprotocols(r *http.Request) []string {
+	var offered []string
+	for _, h := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, name := range strings.Split(h, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				offered = append(offered, name)
+			}
+		}
+	}
+	return offered
+}
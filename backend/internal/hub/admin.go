@@ -0,0 +1,177 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+	"codenames/internal/storage"
+
+	"nhooyr.io/websocket"
+)
+
+// ErrStopped is returned for operations on a hub that was shut down.
+var ErrStopped = errors.New("hub is shut down")
+
+// errCommandPanicked is returned for a command that panicked on the actor.
+var errCommandPanicked = errors.New("room command failed")
+
+// call runs fn on the room's actor and returns its error.
+func (h *Hub) call(ctx context.Context, roomID string, fn func(ctx context.Context, a *roomActor) error) error {
+	done := make(chan error, 1)
+	cmd := func(ctx context.Context, a *roomActor) {
+		defer func() {
+			// Answer the caller, then let the actor log the panic.
+			if r := recover(); r != nil {
+				done <- errCommandPanicked
+				panic(r)
+			}
+		}()
+		done <- fn(ctx, a)
+	}
+	if !h.dispatch(ctx, roomID, cmd) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return ErrStopped
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FinishGame ends the game being played in the room, with the given winner
+// or none.
+func (h *Hub) FinishGame(ctx context.Context, roomID string, winner model.Team) error {
+	return h.call(ctx, roomID, func(ctx context.Context, a *roomActor) error {
+		_, g, _, err := a.updateGame(ctx, func(g model.Game, cards []model.Card) (model.Game, []model.Card, error) {
+			g, err := a.engine.ForceFinish(ctx, g, winner)
+			return g, cards, err
+		})
+		if err != nil {
+			return err
+		}
+		a.publishEvent(a.roomID, protocol.MsgGameFinished, protocol.GameFinished{Winner: g.Winner, Reason: protocol.ReasonEndedByAdmin})
+		a.broadcastRoomState(ctx)
+		return nil
+	})
+}
+
+// ResetGame takes the room back to the lobby, whatever the state of its game.
+func (h *Hub) ResetGame(ctx context.Context, roomID string) error {
+	return h.call(ctx, roomID, func(ctx context.Context, a *roomActor) error {
+		if _, err := a.roomRepo.GetByID(ctx, a.roomID); err != nil {
+			return err
+		}
+		if err := a.resetGame(ctx); err != nil {
+			return fmt.Errorf("reset game: %w", err)
+		}
+		a.broadcastRoomState(ctx)
+		return nil
+	})
+}
+
+// Kick removes the player from the room and closes their connections on
+// every instance with protocol.CloseKicked.
+func (h *Hub) Kick(ctx context.Context, roomID, playerID string) error {
+	return h.call(ctx, roomID, func(ctx context.Context, a *roomActor) error {
+		players, err := a.playerRepo.GetByRoomID(ctx, a.roomID)
+		if err != nil {
+			return fmt.Errorf("kick: %w", err)
+		}
+		i := slices.IndexFunc(players, func(p model.Player) bool { return p.ID == playerID })
+		if i < 0 {
+			return storage.ErrNotFound
+		}
+		if err := a.playerRepo.Delete(ctx, playerID); err != nil {
+			return err
+		}
+		a.cancelOffline(playerID)
+		a.disconnectEverywhere(playerID, protocol.CloseKicked)
+		a.publishEvent(a.roomID, protocol.MsgPlayerLeft, protocol.PlayerPresence{Player: players[i]})
+		a.broadcastRoomState(ctx)
+		return nil
+	})
+}
+
+// DeleteRoom closes every connection to the room with
+// protocol.CloseRoomDeleted and deletes it with everything in it.
+func (h *Hub) DeleteRoom(ctx context.Context, roomID string) (storage.PurgeStats, error) {
+	var stats storage.PurgeStats
+	err := h.call(ctx, roomID, func(ctx context.Context, a *roomActor) error {
+		var err error
+		if stats, err = a.roomRepo.Delete(ctx, a.roomID); err != nil {
+			return err
+		}
+		for playerID, t := range a.offline {
+			t.Stop()
+			delete(a.offline, playerID)
+		}
+		a.invalidate()
+		a.disconnectEverywhere("", protocol.CloseRoomDeleted)
+		return nil
+	})
+	return stats, err
+}
+
+// Announce sends an operators' message to the room's clients on every
+// instance. It fails with storage.ErrNotFound for an unknown room. A room
+// without clients here gets no actor for it, only the relay.
+func (h *Hub) Announce(ctx context.Context, roomID, message string) error {
+	if _, err := h.roomRepo.GetByID(ctx, roomID); err != nil {
+		return err
+	}
+	out := outgoing{msg: protocol.Outgoing{Type: protocol.MsgAnnouncement, Payload: protocol.Announcement{Message: message}}}
+	h.mu.RLock()
+	stopped, local := h.stopped, len(h.rooms[roomID]) > 0
+	h.mu.RUnlock()
+	if stopped {
+		return ErrStopped
+	}
+	if local {
+		err := h.call(ctx, roomID, func(ctx context.Context, a *roomActor) error {
+			a.publish(a.roomID, nil, out)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	h.relay(roomID, out)
+	return nil
+}
+
+// disconnectEverywhere closes the player's connections to the room, or all
+// of them if playerID is empty, on this instance and the others.
+func (a *roomActor) disconnectEverywhere(playerID string, code int) {
+	a.disconnect(playerID, code)
+	a.enqueueRelay(peerMessage{Origin: a.instanceID, RoomID: a.roomID, Type: peerDisconnect, PlayerID: playerID, CloseCode: code})
+}
+
+// disconnect closes the player's local connections to the room, or all of
+// them if playerID is empty. The clients unregister without updating the
+// player's presence.
+func (a *roomActor) disconnect(playerID string, code int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for client := range a.rooms[a.roomID] {
+		if playerID == "" || client.playerID == playerID {
+			client.removed = true
+			go client.conn.Close(websocket.StatusCode(code), "")
+		}
+	}
+}
+
+// Connections returns how many clients are connected to the room on this
+// instance.
+func (h *Hub) Connections(roomID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[roomID])
+}
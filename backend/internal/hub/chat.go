@@ -0,0 +1,116 @@
+package hub
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"codenames/internal/logging"
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+)
+
+const (
+	maxChatLength      = 500
+	defaultChatHistory = 50
+	maxChatHistory     = 100
+)
+
+func (a *roomActor) handleChatSend(ctx context.Context, client *Client, msg *protocol.ChatSend) {
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		client.SendError("message cannot be empty")
+		return
+	}
+	if utf8.RuneCountInString(text) > maxChatLength {
+		client.SendError("message is too long")
+		return
+	}
+
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+	if err != nil {
+		client.SendError("player not found")
+		return
+	}
+
+	chat := model.ChatMessage{
+		RoomID:     client.roomID,
+		PlayerID:   player.ID,
+		PlayerName: player.Name,
+		Channel:    msg.Channel,
+		Text:       text,
+	}
+	switch chat.Channel {
+	case model.ChatChannelRoom:
+	case model.ChatChannelTeam:
+		if player.Team == "" {
+			client.SendError("join a team to use team chat")
+			return
+		}
+		// A spymaster talking to their own operatives mid-turn would be a clue outside the clue.
+		if player.Role == model.RoleSpymaster {
+			g, _, err := a.activeGame(ctx)
+			if err == nil && g.Phase == model.PhasePlaying && g.CurrentTeam == player.Team {
+				client.SendError("spymasters cannot use team chat during their team's turn")
+				return
+			}
+		}
+		chat.Team = player.Team
+	}
+
+	chat, err = a.chatRepo.Create(ctx, chat)
+	if err != nil {
+		client.SendError("failed to send message")
+		return
+	}
+	a.broadcastChat(ctx, chat)
+}
+
+func (a *roomActor) handleChatHistory(ctx context.Context, client *Client, msg *protocol.ChatHistory) {
+	channel := msg.Channel
+
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+	if err != nil {
+		client.SendError("player not found")
+		return
+	}
+	if channel == model.ChatChannelTeam && player.Team == "" {
+		client.SendError("join a team to use team chat")
+		return
+	}
+
+	limit := msg.Limit
+	if limit <= 0 {
+		limit = defaultChatHistory
+	}
+	if limit > maxChatHistory {
+		limit = maxChatHistory
+	}
+
+	messages, err := a.chatRepo.GetHistory(ctx, client.roomID, channel, player.Team, msg.Before, limit)
+	if err != nil {
+		client.SendError("failed to load chat history")
+		return
+	}
+	client.Send(protocol.MsgChatHistory, protocol.ChatHistoryResult{Channel: channel, Messages: messages})
+}
+
+// broadcastChat delivers a chat message to the room on every instance, or
+// only to members of the message's team for the team channel.
+func (h *Hub) broadcastChat(ctx context.Context, chat model.ChatMessage) {
+	var players []model.Player
+	if chat.Channel == model.ChatChannelTeam {
+		var err error
+		players, err = h.playerRepo.GetByRoomID(ctx, chat.RoomID)
+		if err != nil {
+			logging.FromContext(ctx).Error("broadcast chat: get players", "err", err)
+			return
+		}
+	}
+	out := outgoing{
+		msg:  protocol.Outgoing{Type: protocol.MsgChatMessage, Payload: chat},
+		team: chat.Team,
+	}
+	h.publish(chat.RoomID, players, out)
+	h.relay(chat.RoomID, out)
+}
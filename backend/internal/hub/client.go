@@ -2,10 +2,14 @@ package hub
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
+	"codenames/internal/logging"
+	"codenames/internal/metrics"
+	"codenames/internal/protocol"
+
 	"nhooyr.io/websocket"
 )
 
@@ -15,41 +19,80 @@ type Client struct {
 	roomID    string
 	sessionID string
 	playerID  string
+	ip        string
+	version   int
+	encoding  protocol.Encoding
+	lastSeq   uint64
 	send      chan []byte
+	closeOnce sync.Once
+	log       *slog.Logger
+
+	restarting  chan struct{}
+	restartOnce sync.Once
+
+	// removed is set when an operator removed the player or the room. Only
+	// accessed on the room actor's goroutine.
+	removed bool
 }
 
-func NewClient(conn *websocket.Conn, hub *Hub, roomID, sessionID, playerID string) *Client {
+// NewClient creates a client for a connection from ip speaking the negotiated
+// protocol version and encoding. A non-zero lastSeq is the last sequence
+// number the client saw on a previous connection to the room. The client
+// logs with the logger carried by ctx.
+func NewClient(ctx context.Context, conn *websocket.Conn, hub *Hub, roomID, sessionID, playerID, ip string, version int, enc protocol.Encoding, lastSeq uint64) *Client {
 	return &Client{
 		conn:      conn,
 		hub:       hub,
 		roomID:    roomID,
 		sessionID: sessionID,
 		playerID:  playerID,
+		ip:        ip,
+		version:   version,
+		encoding:  enc,
+		lastSeq:   lastSeq,
 		send:      make(chan []byte, 64),
+		log:       logging.FromContext(ctx),
+
+		restarting: make(chan struct{}),
 	}
 }
 
 func (c *Client) ReadPump(ctx context.Context) {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.Unregister(c)
 		c.conn.Close(websocket.StatusNormalClosure, "")
 	}()
 
+	if c.hub.cfg.MaxMessageSize > 0 {
+		c.conn.SetReadLimit(c.hub.cfg.MaxMessageSize)
+	}
 	for {
 		_, data, err := c.conn.Read(ctx)
 		if err != nil {
 			if websocket.CloseStatus(err) != -1 {
-				log.Printf("ws closed: %v", err)
+				c.log.Info("ws closed", "err", err)
 			}
 			return
 		}
 
-		var msg IncomingMessage
-		if err := json.Unmarshal(data, &msg); err != nil {
-			c.SendError("invalid message format")
+		if ok, retry := c.allow(); !ok {
+			metrics.MessagesIn.WithLabelValues(protocol.ErrRateLimited).Inc()
+			c.Send(protocol.MsgError, protocol.Error{
+				Message:      "too many messages, slow down",
+				Code:         protocol.ErrRateLimited,
+				RetryAfterMs: retry.Milliseconds(),
+			})
 			continue
 		}
 
+		msg, err := protocol.Decode(data, c.encoding)
+		if err != nil {
+			metrics.MessagesIn.WithLabelValues("invalid").Inc()
+			c.SendError(err.Error())
+			continue
+		}
+		metrics.MessagesIn.WithLabelValues(protocol.TypeOf(msg)).Inc()
+
 		c.hub.HandleMessage(ctx, c, msg)
 	}
 }
@@ -57,16 +100,83 @@ func (c *Client) ReadPump(ctx context.Context) {
 func (c *Client) WritePump(ctx context.Context) {
 	defer c.conn.Close(websocket.StatusNormalClosure, "")
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go c.heartbeat(ctx)
+
+	msgType := websocket.MessageText
+	if c.encoding.Binary() {
+		msgType = websocket.MessageBinary
+	}
 	for {
 		select {
 		case msg, ok := <-c.send:
 			if !ok {
 				return
 			}
-			writeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-			err := c.conn.Write(writeCtx, websocket.MessageText, msg)
+			if err := c.write(ctx, msgType, msg); err != nil {
+				return
+			}
+		case <-c.restarting:
+			// Send what is queued, the restart notice included, then close.
+			c.flush(ctx, msgType)
+			c.conn.Close(websocket.StatusServiceRestart, "server restarting")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Client) write(ctx context.Context, msgType websocket.MessageType, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return c.conn.Write(ctx, msgType, msg)
+}
+
+// flush writes the queued messages.
+func (c *Client) flush(ctx context.Context, msgType websocket.MessageType) {
+	for {
+		select {
+		case msg, ok := <-c.send:
+			if !ok || c.write(ctx, msgType, msg) != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// restart makes WritePump close the connection with StatusServiceRestart
+// once it has sent the messages already queued, so the client reconnects.
+func (c *Client) restart() {
+	c.restartOnce.Do(func() { close(c.restarting) })
+}
+
+// heartbeat pings the client until ctx is done and closes the connection
+// when a pong doesn't arrive in time, which ends ReadPump and unregisters
+// the client.
+func (c *Client) heartbeat(ctx context.Context) {
+	cfg := c.hub.cfg
+	if cfg.PingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, cfg.PongTimeout)
+			err := c.conn.Ping(pingCtx)
 			cancel()
 			if err != nil {
+				if ctx.Err() == nil {
+					// A peer that doesn't answer pings won't answer a close
+					// frame either.
+					c.log.Warn("ws ping failed", "err", err)
+					c.conn.CloseNow()
+				}
 				return
 			}
 		case <-ctx.Done():
@@ -75,19 +185,34 @@ func (c *Client) WritePump(ctx context.Context) {
 	}
 }
 
-func (c *Client) Send(msg OutgoingMessage) {
-	data, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("marshal error: %v", err)
-		return
+// allow takes a message from the client's IP and session rate limits.
+func (c *Client) allow() (bool, time.Duration) {
+	if ok, retry := c.hub.msgByIP.Allow(c.ip); !ok {
+		return false, retry
 	}
+	return c.hub.msgBySession.Allow(c.sessionID)
+}
+
+// Send publishes a message to this client only.
+func (c *Client) Send(msgType string, payload any) {
+	c.hub.publish(c.roomID, nil, outgoing{msg: protocol.Outgoing{Type: msgType, Payload: payload}, client: c})
+}
+
+func (c *Client) SendError(errMsg string) {
+	c.Send(protocol.MsgError, protocol.Error{Message: errMsg})
+}
+
+// deliver queues an encoded message. A client that cannot keep up is
+// disconnected rather than silently missing messages; it resumes from its
+// last sequence number when it reconnects.
+func (c *Client) deliver(data []byte) {
 	select {
 	case c.send <- data:
 	default:
-		log.Printf("client send buffer full, dropping message")
+		metrics.DroppedMessages.Inc()
+		c.closeOnce.Do(func() {
+			c.log.Warn("client send buffer full, disconnecting")
+			go c.conn.Close(websocket.StatusTryAgainLater, "send buffer full")
+		})
 	}
 }
-
-func (c *Client) SendError(errMsg string) {
-	c.Send(OutgoingMessage{Type: MsgError, Error: errMsg})
-}
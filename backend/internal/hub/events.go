@@ -0,0 +1,53 @@
+package hub
+
+import (
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+)
+
+// publishEvent publishes a single game action on every instance. Events go
+// out just before the room state that results from them, so clients can
+// animate and log the action without diffing states.
+func (h *Hub) publishEvent(roomID, msgType string, payload any) {
+	out := outgoing{msg: protocol.Outgoing{Type: msgType, Payload: payload}}
+	h.publish(roomID, nil, out)
+	h.relay(roomID, out)
+}
+
+// publishGuessEvents reports a guess: the revealed card, then either the end
+// of the game or the end of the turn if the guess caused one.
+func (h *Hub) publishGuessEvents(roomID string, player model.Player, before, after model.Game, card model.Card) {
+	h.publishEvent(roomID, protocol.MsgCardRevealed, protocol.CardRevealed{
+		Player: player,
+		Team:   player.Team,
+		Card:   model.CardToView(card, true),
+	})
+
+	switch {
+	case after.Phase == model.PhaseFinished:
+		reason := protocol.ReasonAllCards
+		if card.CardType == model.CardTypeAssassin {
+			reason = protocol.ReasonAssassin
+		}
+		h.publishEvent(roomID, protocol.MsgGameFinished, protocol.GameFinished{Winner: after.Winner, Reason: reason})
+	case after.CurrentTeam != before.CurrentTeam:
+		reason := protocol.ReasonOutOfGuesses
+		if card.CardType != model.CardType(player.Team) {
+			reason = protocol.ReasonWrongGuess
+		}
+		h.publishEvent(roomID, protocol.MsgTurnEnded, protocol.TurnEnded{Team: after.CurrentTeam, Reason: reason})
+	}
+}
+
+// connectedCount returns how many connections the player has open in the room.
+func (h *Hub) connectedCount(roomID, playerID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	n := 0
+	for client := range h.rooms[roomID] {
+		if client.playerID == playerID {
+			n++
+		}
+	}
+	return n
+}
@@ -2,151 +2,384 @@ package hub
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"codenames/internal/game"
+	"codenames/internal/logging"
+	"codenames/internal/metrics"
 	"codenames/internal/model"
+	"codenames/internal/protocol"
+	"codenames/internal/ratelimit"
 	"codenames/internal/storage"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("codenames/internal/hub")
+
 type Hub struct {
-	rooms      map[string]map[*Client]bool
-	mu         sync.RWMutex
-	register   chan *Client
-	unregister chan *Client
-
-	roomRepo   *storage.RoomRepo
-	playerRepo *storage.PlayerRepo
-	gameRepo   *storage.GameRepo
+	rooms   map[string]map[*Client]bool
+	replays map[string]*replayBuffer
+	actors  map[string]*roomActor
+	mu      sync.RWMutex
+
+	// Once Shutdown sets stopped and closes quit, the room actors stop and
+	// no new one starts.
+	stopped bool
+	quit    chan struct{}
+
+	roomRepo   storage.RoomStore
+	playerRepo storage.PlayerStore
+	gameRepo   storage.GameStore
+	chatRepo   storage.ChatStore
 	engine     *game.Engine
+
+	notifier   storage.Notifier
+	instanceID string
+	relayQueue chan peerMessage
+	running    atomic.Bool // RunPeers is running
+	restarting atomic.Bool // Shutdown has started
+
+	cfg          Config
+	msgByIP      *ratelimit.Limiter
+	msgBySession *ratelimit.Limiter
 }
 
-func NewHub(roomRepo *storage.RoomRepo, playerRepo *storage.PlayerRepo, gameRepo *storage.GameRepo, engine *game.Engine) *Hub {
+// Config sets how the hub checks on and limits its connections.
+type Config struct {
+	// PingInterval is how often a client is pinged, and PongTimeout how
+	// long it has to answer before its connection is closed.
+	PingInterval time.Duration
+	PongTimeout  time.Duration
+	// OfflineGrace is how long a player whose last connection dropped shows
+	// as reconnecting before they are marked offline.
+	OfflineGrace time.Duration
+
+	// MaxMessageSize is the largest client message in bytes; a client
+	// sending a larger one is disconnected.
+	MaxMessageSize int64
+	// Client messages over these limits are dropped with an error.
+	MessagesPerIP      ratelimit.Limit
+	MessagesPerSession ratelimit.Limit
+}
+
+func NewHub(roomRepo storage.RoomStore, playerRepo storage.PlayerStore, gameRepo storage.GameStore, chatRepo storage.ChatStore, engine *game.Engine, notifier storage.Notifier, cfg Config) *Hub {
 	return &Hub{
 		rooms:      make(map[string]map[*Client]bool),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+		replays:    make(map[string]*replayBuffer),
+		actors:     make(map[string]*roomActor),
+		quit:       make(chan struct{}),
 		roomRepo:   roomRepo,
 		playerRepo: playerRepo,
 		gameRepo:   gameRepo,
+		chatRepo:   chatRepo,
 		engine:     engine,
+		notifier:   notifier,
+		instanceID: newInstanceID(),
+		relayQueue: make(chan peerMessage, relayQueueSize),
+
+		cfg:          cfg,
+		msgByIP:      ratelimit.New(cfg.MessagesPerIP),
+		msgBySession: ratelimit.New(cfg.MessagesPerSession),
 	}
 }
 
-func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			if h.rooms[client.roomID] == nil {
-				h.rooms[client.roomID] = make(map[*Client]bool)
-			}
-			h.rooms[client.roomID][client] = true
-			h.mu.Unlock()
+// Register adds the client to its room once the room's actor gets to it.
+func (h *Hub) Register(client *Client) {
+	h.dispatch(context.Background(), client.roomID, func(ctx context.Context, a *roomActor) {
+		a.register(logging.With(ctx, "player_id", client.playerID), client)
+	})
+}
+
+// Unregister removes the client from its room and closes its send channel.
+func (h *Hub) Unregister(client *Client) {
+	h.dispatch(context.Background(), client.roomID, func(ctx context.Context, a *roomActor) {
+		a.unregister(logging.With(ctx, "player_id", client.playerID), client)
+	})
+}
+
+func (a *roomActor) register(ctx context.Context, client *Client) {
+	a.addClient(ctx, client)
+	if a.restarting.Load() {
+		// Connected while the server was shutting down.
+		client.restart()
+		return
+	}
+	// A player back within the grace period never left as far as the
+	// others are concerned.
+	reconnected := a.cancelOffline(client.playerID)
+	_ = a.playerRepo.SetPresence(ctx, client.playerID, model.PresenceOnline)
+	if a.connectedCount(client.roomID, client.playerID) == 1 && !reconnected {
+		a.publishPresence(ctx, client, protocol.MsgPlayerJoined)
+	}
+	a.broadcastRoomState(ctx)
+}
+
+func (a *roomActor) unregister(ctx context.Context, client *Client) {
+	a.mu.Lock()
+	if clients, ok := a.rooms[client.roomID]; ok {
+		if _, exists := clients[client]; exists {
+			delete(clients, client)
+			close(client.send)
+			metrics.ConnectedClients.Dec()
+		}
+		if len(clients) == 0 {
+			delete(a.rooms, client.roomID)
+			metrics.ActiveRooms.Dec()
+			a.releaseReplayBuffer(client.roomID)
+		}
+	}
+	a.mu.Unlock()
+
+	if client.removed {
+		// Kicked or its room deleted: the player is gone.
+		return
+	}
+	if a.connectedCount(client.roomID, client.playerID) == 0 {
+		_ = a.playerRepo.SetPresence(ctx, client.playerID, model.PresenceReconnecting)
+		a.scheduleOffline(client)
+	}
+	a.broadcastRoomState(ctx)
+}
 
-			ctx := context.Background()
-			_ = h.playerRepo.SetOnline(ctx, client.playerID, true)
-			h.broadcastRoomState(ctx, client.roomID)
-
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if clients, ok := h.rooms[client.roomID]; ok {
-				if _, exists := clients[client]; exists {
-					delete(clients, client)
-					close(client.send)
-				}
-				if len(clients) == 0 {
-					delete(h.rooms, client.roomID)
-				}
+// scheduleOffline marks the client's player offline once the grace period
+// is over, unless they reconnect first.
+func (a *roomActor) scheduleOffline(client *Client) {
+	a.cancelOffline(client.playerID)
+	var t *time.Timer
+	t = time.AfterFunc(a.cfg.OfflineGrace, func() {
+		a.dispatch(context.Background(), a.roomID, func(ctx context.Context, a *roomActor) {
+			if a.offline[client.playerID] != t {
+				return
 			}
+			delete(a.offline, client.playerID)
+			a.markOffline(logging.With(ctx, "player_id", client.playerID), client)
+		})
+	})
+	a.offline[client.playerID] = t
+}
+
+// cancelOffline stops the player's grace period, reporting whether one was
+// running.
+func (a *roomActor) cancelOffline(playerID string) bool {
+	t, ok := a.offline[playerID]
+	if ok {
+		t.Stop()
+		delete(a.offline, playerID)
+	}
+	return ok
+}
+
+func (a *roomActor) markOffline(ctx context.Context, client *Client) {
+	if a.connectedCount(client.roomID, client.playerID) > 0 {
+		return
+	}
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+	if err != nil {
+		logging.FromContext(ctx).Error("presence: get player", "err", err)
+		return
+	}
+	if player.Presence != model.PresenceReconnecting {
+		// Reconnected to another instance.
+		return
+	}
+	_ = a.playerRepo.SetPresence(ctx, client.playerID, model.PresenceOffline)
+	a.publishPresence(ctx, client, protocol.MsgPlayerLeft)
+	a.broadcastRoomState(ctx)
+}
+
+// publishPresence reports the client's player joining or leaving the room.
+func (h *Hub) publishPresence(ctx context.Context, client *Client, msgType string) {
+	player, err := h.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+	if err != nil {
+		logging.FromContext(ctx).Error("presence: get player", "err", err)
+		return
+	}
+	h.publishEvent(client.roomID, msgType, protocol.PlayerPresence{Player: player})
+}
+
+// addClient adds the client to its room and, if it is resuming, replays the
+// messages it missed before it can receive any new ones.
+func (h *Hub) addClient(ctx context.Context, client *Client) {
+	var v viewer
+	if client.lastSeq > 0 {
+		player, err := h.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+		if err == nil {
+			v = viewerOf(player)
+		}
+	}
+
+	for {
+		buf := h.replayBuffer(client.roomID)
+		buf.mu.Lock()
+		h.mu.Lock()
+		if h.replays[client.roomID] != buf {
+			// Released while we were waiting for it.
 			h.mu.Unlock()
+			buf.mu.Unlock()
+			continue
+		}
+		if h.rooms[client.roomID] == nil {
+			h.rooms[client.roomID] = make(map[*Client]bool)
+			metrics.ActiveRooms.Inc()
+		}
+		h.rooms[client.roomID][client] = true
+		metrics.ConnectedClients.Inc()
+		h.mu.Unlock()
 
-			ctx := context.Background()
-			_ = h.playerRepo.SetOnline(ctx, client.playerID, false)
-			h.broadcastRoomState(ctx, client.roomID)
+		if client.lastSeq > 0 {
+			h.replay(client, buf, v, client.lastSeq)
 		}
+		buf.mu.Unlock()
+		return
 	}
 }
 
-func (h *Hub) Register(client *Client) {
-	h.register <- client
+// HandleMessage queues a decoded and validated client message for the
+// client's room. Messages of a room are handled one at a time, in order.
+//
+// Each message gets its own trace, linked to the connection's, covering
+// the wait for the room's actor and the handling.
+func (h *Hub) HandleMessage(ctx context.Context, client *Client, msg protocol.Payload) {
+	msgType := protocol.TypeOf(msg)
+	_, span := tracer.Start(ctx, "ws "+msgType,
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("room_id", client.roomID),
+			attribute.String("player_id", client.playerID),
+			attribute.String("message.type", msgType),
+		))
+	ok := h.dispatch(ctx, client.roomID, func(ctx context.Context, a *roomActor) {
+		defer span.End()
+		ctx = trace.ContextWithSpan(ctx, span)
+		ctx = logging.With(ctx, "player_id", client.playerID, "type", msgType)
+		a.handleMessage(ctx, client, msg)
+	})
+	if !ok {
+		span.End()
+	}
 }
 
-func (h *Hub) HandleMessage(ctx context.Context, client *Client, msg IncomingMessage) {
-	switch msg.Type {
-	case MsgJoinTeam:
-		h.handleJoinTeam(ctx, client, msg)
-	case MsgSetRole:
-		h.handleSetRole(ctx, client, msg)
-	case MsgStartGame:
-		h.handleStartGame(ctx, client)
-	case MsgGiveClue:
-		h.handleGiveClue(ctx, client, msg)
-	case MsgGuessCard:
-		h.handleGuessCard(ctx, client, msg)
-	case MsgEndGuessing:
-		h.handleEndGuessing(ctx, client)
-	case MsgNewGame:
-		h.handleNewGame(ctx, client)
+func (a *roomActor) handleMessage(ctx context.Context, client *Client, msg protocol.Payload) {
+	switch msg := msg.(type) {
+	case *protocol.JoinTeam:
+		a.handleJoinTeam(ctx, client, msg)
+	case *protocol.SetRole:
+		a.handleSetRole(ctx, client, msg)
+	case *protocol.StartGame:
+		a.handleStartGame(ctx, client)
+	case *protocol.GiveClue:
+		a.handleGiveClue(ctx, client, msg)
+	case *protocol.GuessCard:
+		a.handleGuessCard(ctx, client, msg)
+	case *protocol.EndGuessing:
+		a.handleEndGuessing(ctx, client)
+	case *protocol.NewGame:
+		a.handleNewGame(ctx, client)
+	case *protocol.UpdateSettings:
+		a.handleUpdateSettings(ctx, client, msg)
+	case *protocol.ChatSend:
+		a.handleChatSend(ctx, client, msg)
+	case *protocol.ChatHistory:
+		a.handleChatHistory(ctx, client, msg)
+	case *protocol.Resume:
+		a.handleResume(ctx, client, msg)
 	default:
-		client.SendError("unknown message type: " + msg.Type)
+		client.SendError(fmt.Sprintf("unsupported message %T", msg))
 	}
 }
 
-func (h *Hub) handleJoinTeam(ctx context.Context, client *Client, msg IncomingMessage) {
-	team := model.Team(msg.Team)
-	if team != model.TeamRed && team != model.TeamBlue && team != "" {
-		client.SendError("invalid team")
+func (a *roomActor) handleJoinTeam(ctx context.Context, client *Client, msg *protocol.JoinTeam) {
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+	if err != nil {
+		client.SendError("player not found")
+		return
+	}
+	if a.spymasterLocked(ctx, player, msg.Team, msg.Role) {
+		client.SendError("spymasters can only change in the lobby")
 		return
 	}
-	role := model.Role(msg.Role)
-	if err := h.playerRepo.SetTeamRole(ctx, client.playerID, team, role); err != nil {
+	if err := a.playerRepo.SetTeamRole(ctx, client.playerID, msg.Team, msg.Role); err != nil {
 		client.SendError("failed to join team")
 		return
 	}
-	h.broadcastRoomState(ctx, client.roomID)
+	a.broadcastRoomState(ctx)
 }
 
-func (h *Hub) handleSetRole(ctx context.Context, client *Client, msg IncomingMessage) {
-	player, err := h.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+func (a *roomActor) handleSetRole(ctx context.Context, client *Client, msg *protocol.SetRole) {
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
 	if err != nil {
 		client.SendError("player not found")
 		return
 	}
-	role := model.Role(msg.Role)
-	if role != model.RoleSpymaster && role != model.RoleOperative {
-		client.SendError("invalid role")
+	if a.spymasterLocked(ctx, player, player.Team, msg.Role) {
+		client.SendError("spymasters can only change in the lobby")
 		return
 	}
-	if err := h.playerRepo.SetTeamRole(ctx, client.playerID, player.Team, role); err != nil {
+	if err := a.playerRepo.SetTeamRole(ctx, client.playerID, player.Team, msg.Role); err != nil {
 		client.SendError("failed to set role")
 		return
 	}
-	h.broadcastRoomState(ctx, client.roomID)
+	a.broadcastRoomState(ctx)
 }
 
-func (h *Hub) handleStartGame(ctx context.Context, client *Client) {
-	players, err := h.playerRepo.GetByRoomID(ctx, client.roomID)
+// spymasterLocked reports whether moving a player to a team and role has to
+// wait for the lobby. Spymasters see the key, so while a game is played
+// nobody becomes one, stops being one or changes sides as one.
+func (a *roomActor) spymasterLocked(ctx context.Context, player model.Player, team model.Team, role model.Role) bool {
+	if player.Role != model.RoleSpymaster && role != model.RoleSpymaster {
+		return false
+	}
+	if player.Team == team && player.Role == role {
+		return false
+	}
+	g, _, err := a.activeGame(ctx)
+	return err == nil && g.Phase == model.PhasePlaying
+}
+
+func (a *roomActor) handleStartGame(ctx context.Context, client *Client) {
+	room, err := a.roomRepo.GetByID(ctx, client.roomID)
+	if err != nil {
+		client.SendError("room not found")
+		return
+	}
+	players, err := a.playerRepo.GetByRoomID(ctx, client.roomID)
 	if err != nil {
 		client.SendError("failed to get players")
 		return
 	}
-	if err := h.engine.CanStartGame(players); err != nil {
+	if !room.Settings.Permissions.StartGame.Allows(findRole(players, client.playerID)) {
+		client.SendError("not allowed to start the game")
+		return
+	}
+	if err := a.engine.CanStartGame(players); err != nil {
+		client.SendError(err.Error())
+		return
+	}
+	g, cards, err := a.engine.StartGame(ctx, client.roomID, room.Settings, players)
+	if errors.Is(err, game.ErrInvalidSettings) {
 		client.SendError(err.Error())
 		return
 	}
-	_, _, err = h.engine.StartGame(ctx, client.roomID)
 	if err != nil {
+		a.invalidate()
 		client.SendError("failed to start game")
 		return
 	}
-	h.broadcastRoomState(ctx, client.roomID)
+	a.setGame(&g, cards)
+	a.broadcastRoomState(ctx)
 }
 
-func (h *Hub) handleGiveClue(ctx context.Context, client *Client, msg IncomingMessage) {
-	player, err := h.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+func (a *roomActor) handleGiveClue(ctx context.Context, client *Client, msg *protocol.GiveClue) {
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
 	if err != nil {
 		client.SendError("player not found")
 		return
@@ -156,26 +389,23 @@ func (h *Hub) handleGiveClue(ctx context.Context, client *Client, msg IncomingMe
 		return
 	}
 
-	g, err := h.gameRepo.GetActiveByRoomID(ctx, client.roomID)
-	if err != nil {
-		client.SendError("no active game")
-		return
-	}
-	if player.Team != g.CurrentTeam {
-		client.SendError("not your team's turn")
-		return
-	}
-
-	g, err = h.engine.GiveClue(ctx, g, msg.Clue, msg.Number)
+	_, g, _, err := a.updateGame(ctx, func(g model.Game, cards []model.Card) (model.Game, []model.Card, error) {
+		if player.Team != g.CurrentTeam {
+			return g, cards, errNotYourTurn
+		}
+		g, err := a.engine.GiveClue(ctx, g, player, msg.Clue, msg.Number)
+		return g, cards, err
+	})
 	if err != nil {
-		client.SendError(err.Error())
+		client.SendError(gameErrorMessage(err))
 		return
 	}
-	h.broadcastRoomState(ctx, client.roomID)
+	a.publishEvent(client.roomID, protocol.MsgClueGiven, protocol.ClueGiven{Player: player, Team: player.Team, Clue: g.CurrentClue, Number: g.CurrentNumber})
+	a.broadcastRoomState(ctx)
 }
 
-func (h *Hub) handleGuessCard(ctx context.Context, client *Client, msg IncomingMessage) {
-	player, err := h.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+func (a *roomActor) handleGuessCard(ctx context.Context, client *Client, msg *protocol.GuessCard) {
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
 	if err != nil {
 		client.SendError("player not found")
 		return
@@ -185,135 +415,184 @@ func (h *Hub) handleGuessCard(ctx context.Context, client *Client, msg IncomingM
 		return
 	}
 
-	g, err := h.gameRepo.GetActiveByRoomID(ctx, client.roomID)
+	before, g, cards, err := a.updateGame(ctx, func(g model.Game, cards []model.Card) (model.Game, []model.Card, error) {
+		return a.engine.GuessCard(ctx, g, cards, msg.CardID, player)
+	})
 	if err != nil {
-		client.SendError("no active game")
+		client.SendError(gameErrorMessage(err))
 		return
 	}
+	for _, c := range cards {
+		if c.ID == msg.CardID {
+			a.publishGuessEvents(client.roomID, player, before, g, c)
+			break
+		}
+	}
+	a.broadcastRoomState(ctx)
+}
 
-	cards, err := h.gameRepo.GetCardsByGameID(ctx, g.ID)
+func (a *roomActor) handleEndGuessing(ctx context.Context, client *Client) {
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
 	if err != nil {
-		client.SendError("failed to get cards")
+		client.SendError("player not found")
 		return
 	}
 
-	g, cards, err = h.engine.GuessCard(ctx, g, cards, msg.CardID, player.Team)
+	_, g, _, err := a.updateGame(ctx, func(g model.Game, cards []model.Card) (model.Game, []model.Card, error) {
+		if player.Team != g.CurrentTeam {
+			return g, cards, errNotYourTurn
+		}
+		g, err := a.engine.EndGuessing(ctx, g, player)
+		return g, cards, err
+	})
 	if err != nil {
-		client.SendError(err.Error())
+		client.SendError(gameErrorMessage(err))
 		return
 	}
-	h.broadcastRoomState(ctx, client.roomID)
+	a.publishEvent(client.roomID, protocol.MsgTurnEnded, protocol.TurnEnded{Player: &player, Team: g.CurrentTeam, Reason: protocol.ReasonEndedByPlayer})
+	a.broadcastRoomState(ctx)
 }
 
-func (h *Hub) handleEndGuessing(ctx context.Context, client *Client) {
-	player, err := h.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
-	if err != nil {
-		client.SendError("player not found")
+func (a *roomActor) handleNewGame(ctx context.Context, client *Client) {
+	if err := a.resetGame(ctx); err != nil {
+		client.SendError("failed to reset")
 		return
 	}
+	a.broadcastRoomState(ctx)
+}
 
-	g, err := h.gameRepo.GetActiveByRoomID(ctx, client.roomID)
+// resetGame takes the room back to the lobby.
+func (a *roomActor) resetGame(ctx context.Context) error {
+	// Deactivate the finished game so it's no longer returned by GetActiveByRoomID
+	activeGame, _, err := a.activeGame(ctx)
+	if err == nil {
+		_ = a.gameRepo.Deactivate(ctx, activeGame.ID)
+	}
+	a.invalidate()
+
+	// Reset the game state — go back to lobby
+	return a.playerRepo.ResetTeamsAndRoles(ctx, a.roomID)
+}
+
+func (a *roomActor) handleUpdateSettings(ctx context.Context, client *Client, msg *protocol.UpdateSettings) {
+	room, err := a.roomRepo.GetByID(ctx, client.roomID)
 	if err != nil {
-		client.SendError("no active game")
+		client.SendError("room not found")
 		return
 	}
-	if player.Team != g.CurrentTeam {
-		client.SendError("not your team's turn")
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+	if err != nil {
+		client.SendError("player not found")
 		return
 	}
-
-	_, err = h.engine.EndGuessing(ctx, g)
-	if err != nil {
+	if !room.Settings.Permissions.EditSettings.Allows(player.Role) {
+		client.SendError("not allowed to edit settings")
+		return
+	}
+	if g, _, err := a.activeGame(ctx); err == nil && g.Phase == model.PhasePlaying {
+		client.SendError("settings can only be changed in the lobby")
+		return
+	}
+	if err := game.ValidateSettings(*msg.Settings); err != nil {
 		client.SendError(err.Error())
 		return
 	}
-	h.broadcastRoomState(ctx, client.roomID)
+	if err := a.roomRepo.UpdateSettings(ctx, client.roomID, *msg.Settings); err != nil {
+		client.SendError("failed to update settings")
+		return
+	}
+	a.broadcastRoomState(ctx)
 }
 
-func (h *Hub) handleNewGame(ctx context.Context, client *Client) {
-	// Deactivate the finished game so it's no longer returned by GetActiveByRoomID
-	activeGame, err := h.gameRepo.GetActiveByRoomID(ctx, client.roomID)
-	if err == nil {
-		_ = h.gameRepo.Deactivate(ctx, activeGame.ID)
+func findRole(players []model.Player, playerID string) model.Role {
+	for _, p := range players {
+		if p.ID == playerID {
+			return p.Role
+		}
 	}
+	return ""
+}
 
-	// Reset the game state — go back to lobby
-	if err := h.playerRepo.ResetTeamsAndRoles(ctx, client.roomID); err != nil {
-		client.SendError("failed to reset")
-		return
-	}
-	h.broadcastRoomState(ctx, client.roomID)
+// broadcastRoomState sends the room state to the room's clients on every instance.
+func (a *roomActor) broadcastRoomState(ctx context.Context) {
+	a.sendRoomState(ctx)
+	a.relay(a.roomID, outgoing{msg: protocol.Outgoing{Type: protocol.MsgRoomState}})
 }
 
-func (h *Hub) broadcastRoomState(ctx context.Context, roomID string) {
-	room, err := h.roomRepo.GetByID(ctx, roomID)
+// sendRoomState sends the room state to the room's clients on this instance.
+func (a *roomActor) sendRoomState(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "hub.sendRoomState")
+	defer span.End()
+	start := time.Now()
+	roomID := a.roomID
+	room, err := a.roomRepo.GetByID(ctx, roomID)
 	if err != nil {
-		log.Printf("broadcast: get room: %v", err)
+		logging.FromContext(ctx).Error("broadcast: get room", "err", err)
 		return
 	}
 
-	players, err := h.playerRepo.GetByRoomID(ctx, roomID)
+	players, err := a.playerRepo.GetByRoomID(ctx, roomID)
 	if err != nil {
-		log.Printf("broadcast: get players: %v", err)
+		logging.FromContext(ctx).Error("broadcast: get players", "err", err)
 		return
 	}
 
-	var g *model.Game
-	var cards []model.Card
-	activeGame, err := h.gameRepo.GetActiveByRoomID(ctx, roomID)
-	if err == nil {
-		g = &activeGame
-		cards, _ = h.gameRepo.GetCardsByGameID(ctx, activeGame.ID)
+	g, cards, err := a.loadGame(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("broadcast: get game", "err", err)
+		return
 	}
 
-	h.mu.RLock()
-	clients := h.rooms[roomID]
-	h.mu.RUnlock()
-
-	for client := range clients {
-		// Determine if this player is a spymaster
-		isSpymaster := false
-		for _, p := range players {
-			if p.SessionID == client.sessionID && p.Role == model.RoleSpymaster {
-				isSpymaster = true
-				break
-			}
-		}
+	state := buildRoomState(room, players, g, cards, false)
+	spymasterState := buildRoomState(room, players, g, cards, true)
+	a.publish(roomID, players, outgoing{
+		msg:       protocol.Outgoing{Type: protocol.MsgRoomState, Payload: state},
+		spymaster: &protocol.Outgoing{Type: protocol.MsgRoomState, Payload: spymasterState},
+	})
+	metrics.BroadcastDuration.Observe(time.Since(start).Seconds())
+}
 
-		// Build card views
-		var cardViews []model.CardView
-		var blueCardsLeft int
-		var redCardsLeft int
+// buildRoomState renders the room as seen by a spymaster or by everyone else.
+func buildRoomState(room model.Room, players []model.Player, g *model.Game, cards []model.Card, isSpymaster bool) *model.RoomState {
+	// Build card views
+	var cardViews []model.CardView
+	var blueCardsLeft int
+	var redCardsLeft int
 
-		for _, c := range cards {
-			showType := isSpymaster || g.Phase == model.PhaseFinished
-			cardViews = append(cardViews, model.CardToView(c, showType))
+	for _, c := range cards {
+		showType := isSpymaster || (g.Phase == model.PhaseFinished && room.Settings.Visibility.RevealKeyOnFinish)
+		cardViews = append(cardViews, model.CardToView(c, showType))
 
-			if !c.Revealed && c.CardType == model.CardTypeBlue {
-				blueCardsLeft++
-			}
-
-			if !c.Revealed && c.CardType == model.CardTypeRed {
-				redCardsLeft++
-			}
+		if !c.Revealed && c.CardType == model.CardTypeBlue {
+			blueCardsLeft++
 		}
 
-		state := &model.RoomState{
-			Room:    room,
-			Players: players,
-			Game:    g,
-			Cards:   cardViews,
-			RedCardsLeft:  redCardsLeft,
-      		BlueCardsLeft: blueCardsLeft,
+		if !c.Revealed && c.CardType == model.CardTypeRed {
+			redCardsLeft++
 		}
+	}
 
-		data, err := json.Marshal(OutgoingMessage{Type: MsgRoomState, State: state})
-		if err != nil {
-			continue
-		}
-		select {
-		case client.send <- data:
-		default:
-		}
+	return &model.RoomState{
+		Room:          room,
+		Players:       players,
+		Game:          g,
+		Cards:         cardViews,
+		RedCardsLeft:  redCardsLeft,
+		BlueCardsLeft: blueCardsLeft,
+	}
+}
+
+// handleResume replays what the client missed after the given sequence number,
+// e.g. after it noticed its connection lagging. Clients ignore messages with a
+// sequence number they have already seen.
+func (a *roomActor) handleResume(ctx context.Context, client *Client, msg *protocol.Resume) {
+	player, err := a.playerRepo.GetBySessionAndRoom(ctx, client.sessionID, client.roomID)
+	if err != nil {
+		client.SendError("player not found")
+		return
 	}
+	buf := a.replayBuffer(client.roomID)
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	a.replay(client, buf, viewerOf(player), msg.LastSeq)
 }
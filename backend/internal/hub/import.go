@@ -0,0 +1,46 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+// ErrGameInProgress is returned for a room whose game is being played.
+var ErrGameInProgress = errors.New("a game is being played in the room")
+
+// ImportGame adds a game played elsewhere to the room and shows it to the
+// room's players as the current game, finished, until one of them takes
+// the room back to the lobby.
+func (h *Hub) ImportGame(ctx context.Context, roomID string, g model.Game, cards []model.Card, history model.GameHistory) (model.Game, error) {
+	var imported model.Game
+	err := h.call(ctx, roomID, func(ctx context.Context, a *roomActor) error {
+		if _, err := a.roomRepo.GetByID(ctx, a.roomID); err != nil {
+			return err
+		}
+		current, _, err := a.activeGame(ctx)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("import game: %w", err)
+		case current.Phase == model.PhasePlaying:
+			return ErrGameInProgress
+		default:
+			if err := a.gameRepo.Deactivate(ctx, current.ID); err != nil {
+				return fmt.Errorf("import game: %w", err)
+			}
+		}
+		a.invalidate()
+
+		imported, err = a.gameRepo.Import(ctx, a.roomID, g, cards, history)
+		if err != nil {
+			return err
+		}
+		a.broadcastRoomState(ctx)
+		return nil
+	})
+	return imported, err
+}
@@ -0,0 +1,172 @@
+package hub
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"codenames/internal/logging"
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+)
+
+// peerChannel is the Postgres notification channel server instances use to
+// tell each other about changes to rooms.
+const peerChannel = "codenames_rooms"
+
+const relayQueueSize = 256
+
+// peerMessage is a message published in a room by another instance.
+// A room_state message carries no payload: receivers rebuild the state
+// from the database.
+type peerMessage struct {
+	Origin  string          `json:"origin"`
+	RoomID  string          `json:"room_id"`
+	Type    string          `json:"type"`
+	Team    model.Team      `json:"team,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// For peerDisconnect: the player to disconnect, everyone when empty,
+	// and the close code.
+	PlayerID  string `json:"player_id,omitempty"`
+	CloseCode int    `json:"close_code,omitempty"`
+}
+
+// peerDisconnect asks the other instances to close connections to a room.
+// It is not a server message and never reaches clients.
+const peerDisconnect = "disconnect"
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// relay queues a room-wide message for the other instances. Messages are
+// notified in the order they were queued.
+func (h *Hub) relay(roomID string, out outgoing) {
+	msg := peerMessage{Origin: h.instanceID, RoomID: roomID, Type: out.msg.Type, Team: out.team}
+	if out.msg.Type != protocol.MsgRoomState && out.msg.Payload != nil {
+		payload, err := json.Marshal(out.msg.Payload)
+		if err != nil {
+			slog.Error("relay: marshal payload", "room_id", roomID, "type", out.msg.Type, "err", err)
+			return
+		}
+		msg.Payload = payload
+	}
+	h.enqueueRelay(msg)
+}
+
+func (h *Hub) enqueueRelay(msg peerMessage) {
+	select {
+	case h.relayQueue <- msg:
+	default:
+		slog.Warn("relay queue full, dropping message", "room_id", msg.RoomID, "type", msg.Type)
+	}
+}
+
+// RunPeers notifies the other instances of relayed messages and publishes
+// theirs to local clients until ctx is done. It then notifies the messages
+// still queued, such as the disconnections of a shutdown, before returning.
+func (h *Hub) RunPeers(ctx context.Context) {
+	h.running.Store(true)
+	defer h.running.Store(false)
+	go h.notifier.Listen(ctx, peerChannel, h.handlePeerMessage)
+
+	for {
+		select {
+		case msg := <-h.relayQueue:
+			h.notify(ctx, msg)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case msg := <-h.relayQueue:
+					h.notify(flushCtx, msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *Hub) notify(ctx context.Context, msg peerMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("relay: marshal message", "room_id", msg.RoomID, "type", msg.Type, "err", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := h.notifier.Notify(ctx, peerChannel, string(data)); err != nil {
+		slog.Error("relay: notify", "room_id", msg.RoomID, "type", msg.Type, "err", err)
+	}
+}
+
+// Running reports whether RunPeers is running, without which room changes
+// don't reach the other instances.
+func (h *Hub) Running() bool {
+	return h.running.Load()
+}
+
+func (h *Hub) handlePeerMessage(payload string) {
+	var msg peerMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		slog.Error("peer message: unmarshal", "err", err)
+		return
+	}
+	if msg.Origin == h.instanceID {
+		return
+	}
+	h.mu.RLock()
+	local := len(h.rooms[msg.RoomID]) > 0
+	h.mu.RUnlock()
+	if !local {
+		return
+	}
+
+	if msg.Type == peerDisconnect {
+		h.dispatch(context.Background(), msg.RoomID, func(ctx context.Context, a *roomActor) {
+			a.disconnect(msg.PlayerID, msg.CloseCode)
+		})
+		return
+	}
+	if msg.Type == protocol.MsgRoomState {
+		// The game may have changed behind the room's actor.
+		h.dispatch(context.Background(), msg.RoomID, func(ctx context.Context, a *roomActor) {
+			ctx = logging.With(ctx, "type", msg.Type, "origin", msg.Origin)
+			a.invalidate()
+			a.sendRoomState(ctx)
+		})
+		return
+	}
+	h.dispatch(context.Background(), msg.RoomID, func(ctx context.Context, a *roomActor) {
+		ctx = logging.With(ctx, "type", msg.Type, "origin", msg.Origin)
+		a.publishPeerMessage(ctx, msg)
+	})
+}
+
+// publishPeerMessage publishes a message from another instance to the local
+// clients of the room.
+func (a *roomActor) publishPeerMessage(ctx context.Context, msg peerMessage) {
+	p, err := protocol.DecodeServerPayload(msg.Type, msg.Payload)
+	if err != nil {
+		logging.FromContext(ctx).Error("peer message: decode payload", "err", err)
+		return
+	}
+	var players []model.Player
+	if msg.Team != "" {
+		if players, err = a.playerRepo.GetByRoomID(ctx, msg.RoomID); err != nil {
+			logging.FromContext(ctx).Error("peer message: get players", "err", err)
+			return
+		}
+	}
+	a.publish(msg.RoomID, players, outgoing{msg: protocol.Outgoing{Type: msg.Type, Payload: p}, team: msg.Team})
+}
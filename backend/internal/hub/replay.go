@@ -0,0 +1,211 @@
+package hub
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"codenames/internal/metrics"
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+)
+
+const (
+	replayBufferSize = 256
+	// replayRetention is how long the replay buffer of an empty room is kept
+	// so that its last players can still resume.
+	replayRetention = 2 * time.Minute
+)
+
+// outgoing is a message addressed to part of a room. When spymaster is set,
+// spymasters receive it instead of msg.
+type outgoing struct {
+	msg       protocol.Outgoing
+	spymaster *protocol.Outgoing
+	team      model.Team // only members of this team, when set
+	client    *Client    // only this connection, when set
+}
+
+// replayEntry is a published message. It is encoded lazily, at most once
+// per encoding and variant, however many clients receive it.
+type replayEntry struct {
+	roomID    string
+	seq       uint64
+	playerID  string
+	team      model.Team
+	msg       protocol.Outgoing
+	spymaster *protocol.Outgoing
+	encoded   [protocol.NumEncodings][2][]byte
+}
+
+// viewer is what decides which messages of a room a player receives.
+type viewer struct {
+	playerID string
+	team     model.Team
+	role     model.Role
+}
+
+func viewerOf(p model.Player) viewer {
+	return viewer{playerID: p.ID, team: p.Team, role: p.Role}
+}
+
+// payloadFor returns the message encoded for the viewer, or nil if the
+// viewer is not part of the entry's audience. The caller must hold the
+// mutex of the buffer the entry belongs to.
+func (e *replayEntry) payloadFor(v viewer, enc protocol.Encoding) []byte {
+	if e.playerID != "" && e.playerID != v.playerID {
+		return nil
+	}
+	if e.team != "" && e.team != v.team {
+		return nil
+	}
+
+	msg, variant := e.msg, 0
+	if e.spymaster != nil && v.role == model.RoleSpymaster {
+		msg, variant = *e.spymaster, 1
+	}
+	if data := e.encoded[enc][variant]; data != nil {
+		return data
+	}
+	data, err := enc.Marshal(msg)
+	if err != nil {
+		slog.Error("publish: marshal message", "room_id", e.roomID, "type", msg.Type, "err", err)
+		return nil
+	}
+	e.encoded[enc][variant] = data
+	return data
+}
+
+// replayBuffer numbers the messages of one room and keeps the most recent
+// ones. mu is held while a message is numbered and fanned out, so clients
+// always receive a room's messages in sequence order.
+type replayBuffer struct {
+	mu      sync.Mutex
+	seq     uint64
+	entries []*replayEntry
+}
+
+// newReplayBuffer starts the sequence at a random multiple of 2^32, so
+// sequence numbers from another buffer of the room, on this instance or
+// another one, are not mistaken for its own when a client resumes.
+// Sequence numbers stay below 2^53 so JavaScript clients can hold them.
+func newReplayBuffer() *replayBuffer {
+	return &replayBuffer{seq: rand.Uint64N(1<<20) << 32}
+}
+
+func (b *replayBuffer) append(e *replayEntry) {
+	if len(b.entries) == replayBufferSize {
+		copy(b.entries, b.entries[1:])
+		b.entries = b.entries[:len(b.entries)-1]
+	}
+	b.entries = append(b.entries, e)
+}
+
+// since returns the entries after seq. It reports false when some of them
+// are no longer buffered or seq is from a buffer that no longer exists.
+func (b *replayBuffer) since(seq uint64) ([]*replayEntry, bool) {
+	if seq > b.seq {
+		return nil, false
+	}
+	if seq == b.seq {
+		return nil, true
+	}
+	if len(b.entries) == 0 || b.entries[0].seq > seq+1 {
+		return nil, false
+	}
+	first := int(seq + 1 - b.entries[0].seq)
+	return b.entries[first:], true
+}
+
+func (h *Hub) replayBuffer(roomID string) *replayBuffer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buf, ok := h.replays[roomID]
+	if !ok {
+		buf = newReplayBuffer()
+		h.replays[roomID] = buf
+	}
+	return buf
+}
+
+// releaseReplayBuffer drops the room's replay buffer once the room has
+// stayed empty for replayRetention.
+func (h *Hub) releaseReplayBuffer(roomID string) {
+	time.AfterFunc(replayRetention, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if len(h.rooms[roomID]) == 0 {
+			delete(h.replays, roomID)
+		}
+	})
+}
+
+// publish gives the message the room's next sequence number, keeps it for
+// replay and delivers it to every connected client in its audience.
+// players is used to resolve team and role audiences and may be nil for
+// messages sent to a single client.
+func (h *Hub) publish(roomID string, players []model.Player, out outgoing) {
+	buf := h.replayBuffer(roomID)
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+
+	buf.seq++
+	entry := &replayEntry{roomID: roomID, seq: buf.seq, team: out.team, msg: out.msg}
+	entry.msg.Seq = buf.seq
+	if out.spymaster != nil {
+		msg := *out.spymaster
+		msg.Seq = buf.seq
+		entry.spymaster = &msg
+	}
+	if out.client != nil {
+		entry.playerID = out.client.playerID
+	}
+	buf.append(entry)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	clients := h.rooms[roomID]
+	if out.client != nil {
+		if clients[out.client] {
+			if data := entry.payloadFor(viewer{playerID: out.client.playerID}, out.client.encoding); data != nil {
+				out.client.deliver(data)
+				metrics.MessagesOut.WithLabelValues(entry.msg.Type).Inc()
+			}
+		}
+		return
+	}
+
+	viewers := make(map[string]viewer, len(players))
+	for _, p := range players {
+		viewers[p.ID] = viewerOf(p)
+	}
+	sent := 0
+	for client := range clients {
+		if data := entry.payloadFor(viewers[client.playerID], client.encoding); data != nil {
+			client.deliver(data)
+			sent++
+		}
+	}
+	metrics.MessagesOut.WithLabelValues(entry.msg.Type).Add(float64(sent))
+}
+
+// replay sends the client the messages of buf after lastSeq that it is in the
+// audience of, or a resync message if they are no longer available.
+// The caller must hold buf.mu.
+func (h *Hub) replay(client *Client, buf *replayBuffer, v viewer, lastSeq uint64) {
+	entries, ok := buf.since(lastSeq)
+	if !ok {
+		data, err := client.encoding.Marshal(protocol.Outgoing{Type: protocol.MsgResync, Seq: buf.seq})
+		if err == nil {
+			client.deliver(data)
+		}
+		return
+	}
+	for _, e := range entries {
+		if data := e.payloadFor(v, client.encoding); data != nil {
+			client.deliver(data)
+			metrics.MessagesOut.WithLabelValues(e.msg.Type).Inc()
+		}
+	}
+}
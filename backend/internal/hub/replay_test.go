@@ -0,0 +1,114 @@
+package hub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+)
+
+// fill numbers n entries into b the way publish does.
+func fill(b *replayBuffer, n int) {
+	for range n {
+		b.seq++
+		b.append(&replayEntry{seq: b.seq, msg: protocol.Outgoing{Type: protocol.MsgRoomState, Seq: b.seq}})
+	}
+}
+
+func TestReplayBufferSince(t *testing.T) {
+	const base = 1 << 32
+	tests := []struct {
+		name      string
+		published int
+		seq       uint64
+		wantOK    bool
+		wantFirst uint64 // seq of the first entry returned
+		wantLen   int
+	}{
+		{name: "nothing published", seq: base, wantOK: true},
+		{name: "up to date", published: 5, seq: base + 5, wantOK: true},
+		{name: "missed some", published: 5, seq: base + 2, wantOK: true, wantFirst: base + 3, wantLen: 3},
+		{name: "missed all", published: 5, seq: base, wantOK: true, wantFirst: base + 1, wantLen: 5},
+		{name: "ahead of the buffer", published: 5, seq: base + 6},
+		{name: "another buffer's sequence", published: 5, seq: 3 << 32},
+		{name: "older buffer's sequence", published: 5, seq: 7},
+		{name: "oldest still buffered", published: replayBufferSize + 10, seq: base + 10, wantOK: true, wantFirst: base + 11, wantLen: replayBufferSize},
+		{name: "no longer buffered", published: replayBufferSize + 10, seq: base + 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &replayBuffer{seq: base}
+			fill(b, tt.published)
+			if len(b.entries) > replayBufferSize {
+				t.Fatalf("buffer holds %d entries, want at most %d", len(b.entries), replayBufferSize)
+			}
+			entries, ok := b.since(tt.seq)
+			if ok != tt.wantOK {
+				t.Fatalf("since(%d) ok = %v, want %v", tt.seq, ok, tt.wantOK)
+			}
+			if len(entries) != tt.wantLen {
+				t.Fatalf("since(%d) returned %d entries, want %d", tt.seq, len(entries), tt.wantLen)
+			}
+			for i, e := range entries {
+				if want := tt.wantFirst + uint64(i); e.seq != want || e.msg.Seq != want {
+					t.Fatalf("entry %d has seq %d, want %d", i, e.seq, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayEntryPayloadFor(t *testing.T) {
+	msg := protocol.Outgoing{Type: protocol.MsgRoomState, Payload: "all"}
+	spymaster := protocol.Outgoing{Type: protocol.MsgRoomState, Payload: "key"}
+	redSpymaster := viewer{playerID: "p1", team: model.TeamRed, role: model.RoleSpymaster}
+	redOperative := viewer{playerID: "p2", team: model.TeamRed, role: model.RoleOperative}
+	blueOperative := viewer{playerID: "p3", team: model.TeamBlue, role: model.RoleOperative}
+	spectator := viewer{playerID: "p4"}
+
+	tests := []struct {
+		name  string
+		entry replayEntry
+		v     viewer
+		want  any // payload received, nil for none
+	}{
+		{"everyone", replayEntry{msg: msg}, spectator, "all"},
+		{"spymaster variant to a spymaster", replayEntry{msg: msg, spymaster: &spymaster}, redSpymaster, "key"},
+		{"spymaster variant to an operative", replayEntry{msg: msg, spymaster: &spymaster}, redOperative, "all"},
+		{"team to a member", replayEntry{msg: msg, team: model.TeamRed}, redOperative, "all"},
+		{"team to the other team", replayEntry{msg: msg, team: model.TeamRed}, blueOperative, nil},
+		{"team to a spectator", replayEntry{msg: msg, team: model.TeamRed}, spectator, nil},
+		{"player to them", replayEntry{msg: msg, playerID: "p3"}, blueOperative, "all"},
+		{"player to another", replayEntry{msg: msg, playerID: "p3"}, redOperative, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, enc := range protocol.Encodings {
+				data := tt.entry.payloadFor(tt.v, enc)
+				if tt.want == nil {
+					if data != nil {
+						t.Errorf("%v: got a message, want none", enc)
+					}
+					continue
+				}
+				if data == nil {
+					t.Fatalf("%v: got no message, want %v", enc, tt.want)
+				}
+				if again := tt.entry.payloadFor(tt.v, enc); &again[0] != &data[0] {
+					t.Errorf("%v: message encoded twice", enc)
+				}
+				if enc != protocol.EncodingJSON {
+					continue
+				}
+				var got protocol.Outgoing
+				if err := json.Unmarshal(data, &got); err != nil {
+					t.Fatalf("unmarshal: %v", err)
+				}
+				if got.Payload != tt.want {
+					t.Errorf("payload = %v, want %v", got.Payload, tt.want)
+				}
+			}
+		})
+	}
+}
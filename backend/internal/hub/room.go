@@ -0,0 +1,221 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"codenames/internal/logging"
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+var errNotYourTurn = errors.New("not your team's turn")
+
+// actorIdleTimeout is how long the actor of a room without clients is kept
+// before it stops.
+const actorIdleTimeout = time.Minute
+
+// command is work for a room, run on the room's actor goroutine.
+type command func(ctx context.Context, a *roomActor)
+
+// roomActor owns one room. It runs the room's registrations, client
+// messages and updates from other instances one at a time, so game commands
+// are always checked against the current state, and it holds the room's
+// active game so commands don't have to read it back from the database.
+type roomActor struct {
+	*Hub
+	roomID string
+	inbox  chan command
+	done   chan struct{}
+
+	// Only accessed on the actor goroutine.
+	loaded  bool
+	game    *model.Game
+	cards   []model.Card
+	offline map[string]*time.Timer // grace period timers by player ID
+}
+
+// actor returns the running actor of the room, starting one if needed, or
+// nil once the hub is shut down.
+func (h *Hub) actor(roomID string) *roomActor {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	a, ok := h.actors[roomID]
+	if !ok && h.stopped {
+		return nil
+	}
+	if !ok {
+		a = &roomActor{
+			Hub:     h,
+			roomID:  roomID,
+			inbox:   make(chan command),
+			done:    make(chan struct{}),
+			offline: make(map[string]*time.Timer),
+		}
+		h.actors[roomID] = a
+		go a.run()
+	}
+	return a
+}
+
+// dispatch hands cmd to the room's actor. It reports false if ctx was done
+// before the actor took it or the hub is shut down.
+func (h *Hub) dispatch(ctx context.Context, roomID string, cmd command) bool {
+	for {
+		a := h.actor(roomID)
+		if a == nil {
+			return false
+		}
+		select {
+		case a.inbox <- cmd:
+			return true
+		case <-a.done:
+			// Stopped after we found it; start a new one.
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (a *roomActor) run() {
+	ctx := logging.With(context.Background(), "room_id", a.roomID)
+	idle := time.NewTimer(actorIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case cmd := <-a.inbox:
+			a.exec(ctx, cmd)
+		case <-idle.C:
+			if a.stop() {
+				return
+			}
+		case <-a.quit:
+			a.halt()
+			return
+		}
+		idle.Reset(actorIdleTimeout)
+	}
+}
+
+// exec runs a command, recovering from a panic so that one failing command
+// doesn't bring down every room. The cached game is dropped, as the command
+// may have left it half updated.
+func (a *roomActor) exec(ctx context.Context, cmd command) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.FromContext(ctx).Error("room command panicked", "panic", r, "stack", string(debug.Stack()))
+			a.invalidate()
+		}
+	}()
+	cmd(ctx, a)
+}
+
+// stop removes the actor if its room has no clients left and no player is
+// still within their grace period.
+func (a *roomActor) stop() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.rooms[a.roomID]) > 0 || len(a.offline) > 0 {
+		return false
+	}
+	delete(a.actors, a.roomID)
+	close(a.done)
+	return true
+}
+
+// halt stops the actor when the hub shuts down. Players still within their
+// grace period are left reconnecting, for the instance they reconnect to.
+func (a *roomActor) halt() {
+	for _, t := range a.offline {
+		t.Stop()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.actors, a.roomID)
+	close(a.done)
+}
+
+// loadGame returns the room's active game and its cards, reading them from
+// the database the first time or after invalidate. The game is nil when the
+// room is in the lobby.
+func (a *roomActor) loadGame(ctx context.Context) (*model.Game, []model.Card, error) {
+	if a.loaded {
+		return a.game, a.cards, nil
+	}
+	g, err := a.gameRepo.GetActiveByRoomID(ctx, a.roomID)
+	if errors.Is(err, storage.ErrNotFound) {
+		a.setGame(nil, nil)
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	cards, err := a.gameRepo.GetCardsByGameID(ctx, g.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load game: %w", err)
+	}
+	a.setGame(&g, cards)
+	return a.game, a.cards, nil
+}
+
+// activeGame returns the game being played or just finished in the room.
+func (a *roomActor) activeGame(ctx context.Context) (model.Game, []model.Card, error) {
+	g, cards, err := a.loadGame(ctx)
+	if err != nil {
+		return model.Game{}, nil, err
+	}
+	if g == nil {
+		return model.Game{}, nil, storage.ErrNotFound
+	}
+	return *g, cards, nil
+}
+
+func (a *roomActor) setGame(g *model.Game, cards []model.Card) {
+	a.game, a.cards, a.loaded = g, cards, true
+}
+
+// invalidate drops the held game, e.g. after another instance changed it
+// or a failed command left it unknown how much was written.
+func (a *roomActor) invalidate() {
+	a.game, a.cards, a.loaded = nil, nil, false
+}
+
+// updateGame runs action against the room's active game and holds the
+// result, returning the game as it was before and after. If another
+// instance changed the game in the meantime, action runs once more against
+// the reloaded game before storage.ErrConflict is returned.
+func (a *roomActor) updateGame(ctx context.Context, action func(g model.Game, cards []model.Card) (model.Game, []model.Card, error)) (before, after model.Game, cards []model.Card, err error) {
+	for attempt := 0; ; attempt++ {
+		before, cards, err = a.activeGame(ctx)
+		if err != nil {
+			return before, before, nil, err
+		}
+		after, cards, err = action(before, slices.Clone(cards))
+		if err != nil {
+			// Whatever failed, the held game may no longer match the database.
+			a.invalidate()
+			if errors.Is(err, storage.ErrConflict) && attempt == 0 {
+				continue
+			}
+			return before, after, cards, err
+		}
+		a.setGame(&after, cards)
+		return before, after, cards, nil
+	}
+}
+
+// gameErrorMessage is the error shown to a player whose game command failed.
+func gameErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "no active game"
+	case errors.Is(err, storage.ErrConflict):
+		return "the game has changed, please try again"
+	default:
+		return err.Error()
+	}
+}
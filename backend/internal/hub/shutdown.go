@@ -0,0 +1,79 @@
+package hub
+
+import (
+	"context"
+	"time"
+
+	"codenames/internal/protocol"
+)
+
+// Shutdown tells every local client that the server is restarting and closes
+// their connections with StatusServiceRestart, so they reconnect to another
+// instance. Once they are unregistered it stops the room actors, waiting for
+// the commands they are running, and the database writes those make, to
+// finish. It returns ctx's error if that takes longer than ctx allows.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.restarting.Store(true)
+
+	h.mu.RLock()
+	roomIDs := make([]string, 0, len(h.rooms))
+	for roomID := range h.rooms {
+		roomIDs = append(roomIDs, roomID)
+	}
+	h.mu.RUnlock()
+	for _, roomID := range roomIDs {
+		h.dispatch(ctx, roomID, func(ctx context.Context, a *roomActor) {
+			a.restartClients()
+		})
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for h.clientCount() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	h.mu.Lock()
+	h.stopped = true
+	close(h.quit)
+	actors := make([]*roomActor, 0, len(h.actors))
+	for _, a := range h.actors {
+		actors = append(actors, a)
+	}
+	h.mu.Unlock()
+	for _, a := range actors {
+		select {
+		case <-a.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// restartClients sends the room's clients the restart notice and has them
+// reconnect.
+func (a *roomActor) restartClients() {
+	a.publish(a.roomID, nil, outgoing{msg: protocol.Outgoing{Type: protocol.MsgServerRestarting}})
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for client := range a.rooms[a.roomID] {
+		client.restart()
+	}
+}
+
+// clientCount returns how many clients are connected to this instance.
+func (h *Hub) clientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	n := 0
+	for _, clients := range h.rooms {
+		n += len(clients)
+	}
+	return n
+}
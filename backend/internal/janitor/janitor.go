@@ -0,0 +1,90 @@
+// Package janitor periodically removes rooms nobody uses any more and the
+// boards of old games.
+package janitor
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"codenames/internal/storage"
+)
+
+// Config sets how long data is kept. A zero retention keeps it forever.
+type Config struct {
+	RoomRetention time.Duration // delete rooms idle for this long
+	GameRetention time.Duration // archive replaced games started this long ago
+	Interval      time.Duration
+}
+
+type Janitor struct {
+	store storage.JanitorStore
+	cfg   Config
+
+	mu      sync.Mutex
+	removed storage.PurgeStats
+	lastRun time.Time
+}
+
+func New(store storage.JanitorStore, cfg Config) *Janitor {
+	return &Janitor{store: store, cfg: cfg}
+}
+
+// Run cleans up right away and then every interval until ctx is done.
+func (j *Janitor) Run(ctx context.Context) {
+	if j.cfg.RoomRetention <= 0 && j.cfg.GameRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := j.RunOnce(ctx); err != nil {
+			slog.Error("janitor failed", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce cleans up once and returns what it removed.
+func (j *Janitor) RunOnce(ctx context.Context) (storage.PurgeStats, error) {
+	var stats storage.PurgeStats
+	now := time.Now()
+	if j.cfg.RoomRetention > 0 {
+		removed, err := j.store.DeleteIdleRooms(ctx, now.Add(-j.cfg.RoomRetention))
+		if err != nil {
+			return stats, err
+		}
+		stats.Add(removed)
+	}
+	if j.cfg.GameRetention > 0 {
+		removed, err := j.store.ArchiveGames(ctx, now.Add(-j.cfg.GameRetention))
+		if err != nil {
+			return stats, err
+		}
+		stats.Add(removed)
+	}
+
+	j.mu.Lock()
+	j.removed.Add(stats)
+	j.lastRun = now
+	j.mu.Unlock()
+
+	if stats != (storage.PurgeStats{}) {
+		slog.Info("janitor removed old data", "rooms", stats.Rooms, "players", stats.Players,
+			"chat_messages", stats.ChatMessages, "games", stats.Games, "cards", stats.Cards)
+	}
+	return stats, nil
+}
+
+// Removed returns the number of rows removed since the janitor started and
+// when it last ran.
+func (j *Janitor) Removed() (storage.PurgeStats, time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.removed, j.lastRun
+}
@@ -0,0 +1,93 @@
+// Package metrics defines the server's Prometheus metrics and serves them.
+package metrics
+
+import (
+	"net/http"
+
+	"codenames/internal/janitor"
+	"codenames/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "codenames"
+
+var (
+	ActiveRooms = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_rooms",
+		Help:      "Rooms with at least one client connected to this instance.",
+	})
+	ConnectedClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connected_clients",
+		Help:      "WebSocket clients connected to this instance.",
+	})
+	MessagesIn = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_in_total",
+		Help:      "Client messages received, by type. Undecodable messages have type \"invalid\", rate limited ones \"rate_limited\".",
+	}, []string{"type"})
+	MessagesOut = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_out_total",
+		Help:      "Messages queued for clients, by type.",
+	}, []string{"type"})
+	DroppedMessages = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dropped_messages_total",
+		Help:      "Messages dropped because a client's send buffer was full.",
+	})
+	BroadcastDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "broadcast_duration_seconds",
+		Help:      "Time to load a room's state and queue it for the room's clients.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	})
+	DBDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_duration_seconds",
+		Help:      "Storage call latency, by repository and method.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"repo", "method"})
+	GamesStarted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_started_total",
+		Help:      "Games started.",
+	})
+	GamesFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_finished_total",
+		Help:      "Games finished, by winning team and reason.",
+	}, []string{"winner", "reason"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterJanitor exports how much the janitor has removed.
+func RegisterJanitor(j *janitor.Janitor) {
+	for _, c := range []struct {
+		name, help string
+		count      func(s storage.PurgeStats) int64
+	}{
+		{"rooms", "Idle rooms deleted by the janitor.", func(s storage.PurgeStats) int64 { return s.Rooms }},
+		{"players", "Players deleted with idle rooms.", func(s storage.PurgeStats) int64 { return s.Players }},
+		{"games", "Games deleted with idle rooms.", func(s storage.PurgeStats) int64 { return s.Games }},
+		{"cards", "Cards deleted with idle rooms or archived games.", func(s storage.PurgeStats) int64 { return s.Cards }},
+		{"chat_messages", "Chat messages deleted with idle rooms.", func(s storage.PurgeStats) int64 { return s.ChatMessages }},
+	} {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "janitor_removed_" + c.name + "_total",
+			Help:      c.help,
+		}, func() float64 {
+			removed, _ := j.Removed()
+			return float64(c.count(removed))
+		})
+	}
+}
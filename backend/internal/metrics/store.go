@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+// InstrumentStore returns s with the latency of every repository call
+// recorded in DBDuration.
+func InstrumentStore(s storage.Store) storage.Store {
+	s.Rooms = roomStore{s.Rooms}
+	s.Players = playerStore{s.Players}
+	s.Games = gameStore{s.Games}
+	s.Chat = chatStore{s.Chat}
+	s.Ratings = ratingStore{s.Ratings}
+	s.Users = userStore{s.Users}
+	s.Janitor = janitorStore{s.Janitor}
+	s.WordPacks = wordPackStore{s.WordPacks}
+	s.Stats = statsStore{s.Stats}
+	return s
+}
+
+func observe(repo, method string, start time.Time) {
+	DBDuration.WithLabelValues(repo, method).Observe(time.Since(start).Seconds())
+}
+
+type roomStore struct{ s storage.RoomStore }
+
+func (r roomStore) Create(ctx context.Context) (model.Room, error) {
+	defer observe("rooms", "Create", time.Now())
+	return r.s.Create(ctx)
+}
+
+func (r roomStore) GetByID(ctx context.Context, id string) (model.Room, error) {
+	defer observe("rooms", "GetByID", time.Now())
+	return r.s.GetByID(ctx, id)
+}
+
+func (r roomStore) UpdateSettings(ctx context.Context, id string, settings model.RoomSettings) error {
+	defer observe("rooms", "UpdateSettings", time.Now())
+	return r.s.UpdateSettings(ctx, id, settings)
+}
+
+func (r roomStore) List(ctx context.Context) ([]model.RoomSummary, error) {
+	defer observe("rooms", "List", time.Now())
+	return r.s.List(ctx)
+}
+
+func (r roomStore) Delete(ctx context.Context, id string) (storage.PurgeStats, error) {
+	defer observe("rooms", "Delete", time.Now())
+	return r.s.Delete(ctx, id)
+}
+
+type playerStore struct{ s storage.PlayerStore }
+
+func (r playerStore) Upsert(ctx context.Context, roomID, sessionID, name string) (model.Player, error) {
+	defer observe("players", "Upsert", time.Now())
+	return r.s.Upsert(ctx, roomID, sessionID, name)
+}
+
+func (r playerStore) GetByRoomID(ctx context.Context, roomID string) ([]model.Player, error) {
+	defer observe("players", "GetByRoomID", time.Now())
+	return r.s.GetByRoomID(ctx, roomID)
+}
+
+func (r playerStore) GetBySessionAndRoom(ctx context.Context, sessionID, roomID string) (model.Player, error) {
+	defer observe("players", "GetBySessionAndRoom", time.Now())
+	return r.s.GetBySessionAndRoom(ctx, sessionID, roomID)
+}
+
+func (r playerStore) SetTeamRole(ctx context.Context, playerID string, team model.Team, role model.Role) error {
+	defer observe("players", "SetTeamRole", time.Now())
+	return r.s.SetTeamRole(ctx, playerID, team, role)
+}
+
+func (r playerStore) SetPresence(ctx context.Context, playerID string, presence model.Presence) error {
+	defer observe("players", "SetPresence", time.Now())
+	return r.s.SetPresence(ctx, playerID, presence)
+}
+
+func (r playerStore) ResetTeamsAndRoles(ctx context.Context, roomID string) error {
+	defer observe("players", "ResetTeamsAndRoles", time.Now())
+	return r.s.ResetTeamsAndRoles(ctx, roomID)
+}
+
+func (r playerStore) Delete(ctx context.Context, playerID string) error {
+	defer observe("players", "Delete", time.Now())
+	return r.s.Delete(ctx, playerID)
+}
+
+type gameStore struct{ s storage.GameStore }
+
+func (r gameStore) CreateWithCards(ctx context.Context, roomID string, firstTeam model.Team, cards []model.Card, players []model.GamePlayer) (model.Game, []model.Card, error) {
+	defer observe("games", "CreateWithCards", time.Now())
+	return r.s.CreateWithCards(ctx, roomID, firstTeam, cards, players)
+}
+
+func (r gameStore) Import(ctx context.Context, roomID string, g model.Game, cards []model.Card, history model.GameHistory) (model.Game, error) {
+	defer observe("games", "Import", time.Now())
+	return r.s.Import(ctx, roomID, g, cards, history)
+}
+
+func (r gameStore) GetActiveByRoomID(ctx context.Context, roomID string) (model.Game, error) {
+	defer observe("games", "GetActiveByRoomID", time.Now())
+	return r.s.GetActiveByRoomID(ctx, roomID)
+}
+
+func (r gameStore) Save(ctx context.Context, g model.Game, move model.Move, revealed []model.Card) (int, error) {
+	defer observe("games", "Save", time.Now())
+	return r.s.Save(ctx, g, move, revealed)
+}
+
+func (r gameStore) GetByID(ctx context.Context, id string) (model.Game, error) {
+	defer observe("games", "GetByID", time.Now())
+	return r.s.GetByID(ctx, id)
+}
+
+func (r gameStore) GetCardsByGameID(ctx context.Context, gameID string) ([]model.Card, error) {
+	defer observe("games", "GetCardsByGameID", time.Now())
+	return r.s.GetCardsByGameID(ctx, gameID)
+}
+
+func (r gameStore) GetHistory(ctx context.Context, gameID string) (model.GameHistory, error) {
+	defer observe("games", "GetHistory", time.Now())
+	return r.s.GetHistory(ctx, gameID)
+}
+
+func (r gameStore) Deactivate(ctx context.Context, gameID string) error {
+	defer observe("games", "Deactivate", time.Now())
+	return r.s.Deactivate(ctx, gameID)
+}
+
+type chatStore struct{ s storage.ChatStore }
+
+func (r chatStore) Create(ctx context.Context, m model.ChatMessage) (model.ChatMessage, error) {
+	defer observe("chat", "Create", time.Now())
+	return r.s.Create(ctx, m)
+}
+
+func (r chatStore) GetHistory(ctx context.Context, roomID string, channel model.ChatChannel, team model.Team, beforeID int64, limit int) ([]model.ChatMessage, error) {
+	defer observe("chat", "GetHistory", time.Now())
+	return r.s.GetHistory(ctx, roomID, channel, team, beforeID, limit)
+}
+
+type ratingStore struct{ s storage.RatingStore }
+
+func (r ratingStore) GetBySessions(ctx context.Context, sessionIDs []string) ([]model.Rating, error) {
+	defer observe("ratings", "GetBySessions", time.Now())
+	return r.s.GetBySessions(ctx, sessionIDs)
+}
+
+func (r ratingStore) ApplyGameResult(ctx context.Context, gameID, roomID string, changes []model.RatingChange) error {
+	defer observe("ratings", "ApplyGameResult", time.Now())
+	return r.s.ApplyGameResult(ctx, gameID, roomID, changes)
+}
+
+func (r ratingStore) Leaderboard(ctx context.Context, role model.Role, limit int) ([]model.Rating, error) {
+	defer observe("ratings", "Leaderboard", time.Now())
+	return r.s.Leaderboard(ctx, role, limit)
+}
+
+func (r ratingStore) RoomLeaderboard(ctx context.Context, roomID string, role model.Role, limit int) ([]model.Rating, error) {
+	defer observe("ratings", "RoomLeaderboard", time.Now())
+	return r.s.RoomLeaderboard(ctx, roomID, role, limit)
+}
+
+type userStore struct{ s storage.UserStore }
+
+func (r userStore) Create(ctx context.Context, username, passwordHash, sessionID string) (model.User, error) {
+	defer observe("users", "Create", time.Now())
+	return r.s.Create(ctx, username, passwordHash, sessionID)
+}
+
+func (r userStore) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	defer observe("users", "GetByUsername", time.Now())
+	return r.s.GetByUsername(ctx, username)
+}
+
+type janitorStore struct{ s storage.JanitorStore }
+
+func (r janitorStore) DeleteIdleRooms(ctx context.Context, idleSince time.Time) (storage.PurgeStats, error) {
+	defer observe("janitor", "DeleteIdleRooms", time.Now())
+	return r.s.DeleteIdleRooms(ctx, idleSince)
+}
+
+func (r janitorStore) ArchiveGames(ctx context.Context, startedBefore time.Time) (storage.PurgeStats, error) {
+	defer observe("janitor", "ArchiveGames", time.Now())
+	return r.s.ArchiveGames(ctx, startedBefore)
+}
+
+type wordPackStore struct{ s storage.WordPackStore }
+
+func (r wordPackStore) Save(ctx context.Context, name string, pack model.WordPack) error {
+	defer observe("word_packs", "Save", time.Now())
+	return r.s.Save(ctx, name, pack)
+}
+
+func (r wordPackStore) List(ctx context.Context) (map[string]model.WordPack, error) {
+	defer observe("word_packs", "List", time.Now())
+	return r.s.List(ctx)
+}
+
+type statsStore struct{ s storage.StatsStore }
+
+func (r statsStore) Usage(ctx context.Context, since time.Time) (storage.UsageStats, error) {
+	defer observe("stats", "Usage", time.Now())
+	return r.s.Usage(ctx, since)
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type ChatChannel string
+
+const (
+	ChatChannelRoom ChatChannel = "room"
+	ChatChannelTeam ChatChannel = "team"
+)
+
+// ChatMessage is a message posted to the room-wide channel or to one team's channel.
+// Team is only set for team channel messages.
+type ChatMessage struct {
+	ID         int64       `json:"id"`
+	RoomID     string      `json:"room_id"`
+	PlayerID   string      `json:"player_id"`
+	PlayerName string      `json:"player_name"`
+	Channel    ChatChannel `json:"channel"`
+	Team       Team        `json:"team,omitempty"`
+	Text       string      `json:"text"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
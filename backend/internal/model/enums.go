@@ -29,6 +29,17 @@ const (
 	PhaseFinished Phase = "finished"
 )
 
+// Presence is whether a player is connected to their room. A player whose
+// last connection dropped is reconnecting for a grace period before going
+// offline.
+type Presence string
+
+const (
+	PresenceOnline       Presence = "online"
+	PresenceReconnecting Presence = "reconnecting"
+	PresenceOffline      Presence = "offline"
+)
+
 type CardType string
 
 const (
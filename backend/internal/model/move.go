@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// MoveKind is the action a move records.
+type MoveKind string
+
+const (
+	MoveClue        MoveKind = "clue"
+	MoveGuess       MoveKind = "guess"
+	MoveEndGuessing MoveKind = "end_guessing"
+	// MoveFinish is a game ended by an operator rather than by a guess.
+	MoveFinish MoveKind = "finish"
+)
+
+// Move is one action of a game. Seq is the game version the move produced,
+// so moves sort in the order they were played.
+type Move struct {
+	GameID string   `json:"game_id"`
+	Seq    int      `json:"seq"`
+	Kind   MoveKind `json:"kind"`
+	// Team is the team whose turn it was, Player the name of who moved; it
+	// is empty for moves made by an operator.
+	Team   Team   `json:"team"`
+	Player string `json:"player"`
+	// Clue and Number are set for clues, Position for guesses and Winner
+	// for finishes.
+	Clue      string    `json:"clue"`
+	Number    int       `json:"number"`
+	Position  int       `json:"position"`
+	Winner    Team      `json:"winner"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GamePlayer is a player as they were when the game started. PlayerID is
+// who it was in the room, empty for games from records.
+type GamePlayer struct {
+	PlayerID string `json:"player_id,omitempty"`
+	Name     string `json:"name"`
+	Team     Team   `json:"team"`
+	Role     Role   `json:"role"`
+}
+
+// GameHistory is how a game was played: who played, which team started
+// and every move since. Games started before moves were recorded have no
+// first team and no moves.
+type GameHistory struct {
+	FirstTeam Team         `json:"first_team"`
+	Players   []GamePlayer `json:"players"`
+	Moves     []Move       `json:"moves"`
+	StartedAt time.Time    `json:"started_at"`
+}
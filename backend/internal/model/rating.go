@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Rating is a player's Elo rating in one role. Players are identified
+// across rooms by their session.
+type Rating struct {
+	SessionID  string    `json:"-"`
+	PlayerName string    `json:"player_name"`
+	Role       Role      `json:"role"`
+	Rating     float64   `json:"rating"`
+	Games      int       `json:"games"`
+	Wins       int       `json:"wins"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RatingChange is one player's rating update from a finished game.
+type RatingChange struct {
+	SessionID  string
+	PlayerName string
+	Role       Role
+	Before     float64
+	After      float64
+	Won        bool
+}
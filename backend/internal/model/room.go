@@ -3,18 +3,22 @@ package model
 import "time"
 
 type Room struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string       `json:"id"`
+	Settings  RoomSettings `json:"settings"`
+	CreatedAt time.Time    `json:"created_at"`
 }
 
+// Player is a member of a room. IsOnline stays true while the player is
+// reconnecting.
 type Player struct {
-	ID        string `json:"id"`
-	RoomID    string `json:"room_id"`
-	SessionID string `json:"-"`
-	Name      string `json:"name"`
-	Team      Team   `json:"team"`
-	Role      Role   `json:"role"`
-	IsOnline  bool   `json:"is_online"`
+	ID        string   `json:"id"`
+	RoomID    string   `json:"room_id"`
+	SessionID string   `json:"-"`
+	Name      string   `json:"name"`
+	Team      Team     `json:"team"`
+	Role      Role     `json:"role"`
+	IsOnline  bool     `json:"is_online"`
+	Presence  Presence `json:"presence"`
 }
 
 type Game struct {
@@ -26,6 +30,7 @@ type Game struct {
 	CurrentNumber int    `json:"current_number"`
 	GuessesLeft   int    `json:"guesses_left"`
 	Winner        Team   `json:"winner"`
+	Version       int    `json:"version"`
 }
 
 type Card struct {
@@ -71,3 +76,14 @@ func CardToView(c Card, showType bool) CardView {
 	}
 	return cv
 }
+
+// RoomSummary is a room as listed to operators. Phase is the phase of the
+// room's active game, or lobby.
+type RoomSummary struct {
+	ID            string    `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastActiveAt  time.Time `json:"last_active_at"`
+	Players       int       `json:"players"`
+	OnlinePlayers int       `json:"online_players"`
+	Phase         Phase     `json:"phase"`
+}
@@ -0,0 +1,85 @@
+package model
+
+type Variant string
+
+const (
+	VariantClassic Variant = "classic"
+)
+
+type Permission string
+
+const (
+	PermissionAnyone     Permission = "anyone"
+	PermissionSpymasters Permission = "spymasters"
+)
+
+// RoomSettings is the per-room configuration edited in the lobby.
+type RoomSettings struct {
+	Variant     Variant            `json:"variant"`
+	Board       BoardSpec          `json:"board"`
+	Timers      TimerSettings      `json:"timers"`
+	Language    string             `json:"language"`
+	WordPacks   []string           `json:"word_packs"`
+	Visibility  VisibilitySettings `json:"visibility"`
+	Permissions PermissionSettings `json:"permissions"`
+}
+
+// BoardSpec describes the board layout. Cards not assigned to a team
+// or to the assassin are neutral.
+type BoardSpec struct {
+	Size            int `json:"size"`
+	FirstTeamCards  int `json:"first_team_cards"`
+	SecondTeamCards int `json:"second_team_cards"`
+	Assassins       int `json:"assassins"`
+}
+
+func (b BoardSpec) NeutralCards() int {
+	return b.Size - b.FirstTeamCards - b.SecondTeamCards - b.Assassins
+}
+
+// TimerSettings holds turn time limits in seconds. Zero disables the timer.
+type TimerSettings struct {
+	ClueSeconds  int `json:"clue_seconds"`
+	GuessSeconds int `json:"guess_seconds"`
+}
+
+type VisibilitySettings struct {
+	RevealKeyOnFinish bool `json:"reveal_key_on_finish"`
+}
+
+type PermissionSettings struct {
+	EditSettings Permission `json:"edit_settings"`
+	StartGame    Permission `json:"start_game"`
+}
+
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{
+		Variant: VariantClassic,
+		Board: BoardSpec{
+			Size:            25,
+			FirstTeamCards:  9,
+			SecondTeamCards: 8,
+			Assassins:       1,
+		},
+		Language:  "ru",
+		WordPacks: []string{"ru-classic"},
+		Visibility: VisibilitySettings{
+			RevealKeyOnFinish: true,
+		},
+		Permissions: PermissionSettings{
+			EditSettings: PermissionAnyone,
+			StartGame:    PermissionAnyone,
+		},
+	}
+}
+
+// Allows reports whether a player with the given role is granted the permission.
+func (p Permission) Allows(role Role) bool {
+	switch p {
+	case PermissionAnyone:
+		return true
+	case PermissionSpymasters:
+		return role == RoleSpymaster
+	}
+	return false
+}
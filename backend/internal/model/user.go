@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// User is a registered account. SessionID is the identity the account plays
+// under; a guest upgrading to an account keeps their guest session.
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	SessionID    string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// WordPack is a named list of words in a single language.
+type WordPack struct {
+	Language string   `json:"language"`
+	Words    []string `json:"words"`
+}
@@ -0,0 +1,100 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoding is the wire format of a connection, chosen with the subprotocol:
+// codenames.v1 is JSON, codenames.v1.msgpack is MessagePack. MessagePack
+// uses the same field names as JSON.
+type Encoding int
+
+const (
+	EncodingJSON Encoding = iota
+	EncodingMsgPack
+
+	NumEncodings = 2
+)
+
+// Encodings lists the encodings in the order the server prefers them.
+var Encodings = []Encoding{EncodingJSON, EncodingMsgPack}
+
+// suffix is appended to the versioned subprotocol name.
+func (e Encoding) suffix() string {
+	if e == EncodingMsgPack {
+		return ".msgpack"
+	}
+	return ""
+}
+
+// Binary reports whether messages are sent as binary WebSocket frames.
+func (e Encoding) Binary() bool {
+	return e == EncodingMsgPack
+}
+
+func (e Encoding) Marshal(v any) ([]byte, error) {
+	if e == EncodingMsgPack {
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		enc.UseCompactInts(true)
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(v)
+}
+
+// decodeStrict decodes a single value, rejecting unknown fields.
+func (e Encoding) decodeStrict(data []byte, v any) error {
+	if e == EncodingMsgPack {
+		r := bytes.NewReader(data)
+		dec := msgpack.NewDecoder(r)
+		dec.SetCustomStructTag("json")
+		dec.DisallowUnknownFields(true)
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+		if r.Len() > 0 {
+			return errors.New("unexpected data after message")
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after message")
+	}
+	return nil
+}
+
+// envelope reads the type of a client message and keeps its payload encoded.
+func (e Encoding) envelope(data []byte) (string, []byte, error) {
+	if e == EncodingMsgPack {
+		var env struct {
+			Type    string             `json:"type"`
+			Payload msgpack.RawMessage `json:"payload,omitempty"`
+		}
+		err := e.decodeStrict(data, &env)
+		if bytes.Equal(env.Payload, []byte{0xc0}) { // nil
+			env.Payload = nil
+		}
+		return env.Type, env.Payload, err
+	}
+
+	var env Incoming
+	err := e.decodeStrict(data, &env)
+	if bytes.Equal(env.Payload, []byte("null")) {
+		env.Payload = nil
+	}
+	return env.Type, env.Payload, err
+}
@@ -0,0 +1,303 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"codenames/internal/model"
+)
+
+// Client-to-server message types
+const (
+	MsgJoinTeam    = "join_team"
+	MsgSetRole     = "set_role"
+	MsgStartGame   = "start_game"
+	MsgGiveClue    = "give_clue"
+	MsgGuessCard   = "guess_card"
+	MsgEndGuessing = "end_guessing"
+	MsgNewGame     = "new_game"
+
+	MsgUpdateSettings = "update_settings"
+
+	MsgChatSend    = "chat_send"
+	MsgChatHistory = "chat_history"
+
+	MsgResume = "resume"
+)
+
+// Server-to-client message types
+const (
+	MsgRoomState   = "room_state"
+	MsgError       = "error"
+	MsgChatMessage = "chat_message"
+	MsgResync      = "resync"
+	// MsgServerRestarting tells clients their connection is about to be
+	// closed so that they reconnect, possibly to another instance.
+	MsgServerRestarting = "server_restarting"
+	// MsgAnnouncement is a message from the server's operators.
+	MsgAnnouncement = "announcement"
+
+	MsgClueGiven    = "clue_given"
+	MsgCardRevealed = "card_revealed"
+	MsgTurnEnded    = "turn_ended"
+	MsgGameFinished = "game_finished"
+	MsgPlayerJoined = "player_joined"
+	MsgPlayerLeft   = "player_left"
+)
+
+// Error codes.
+const (
+	// ErrRateLimited means the message was dropped because the client sent
+	// too many.
+	ErrRateLimited = "rate_limited"
+)
+
+// WebSocket close codes telling a client not to reconnect.
+const (
+	// CloseKicked means the player was removed from the room.
+	CloseKicked = 4001
+	// CloseRoomDeleted means the room no longer exists.
+	CloseRoomDeleted = 4004
+)
+
+// Why a turn ended or a game finished.
+const (
+	ReasonWrongGuess    = "wrong_guess"
+	ReasonOutOfGuesses  = "out_of_guesses"
+	ReasonEndedByPlayer = "ended_by_player"
+	ReasonEndedByAdmin  = "ended_by_admin"
+	ReasonAssassin      = "assassin"
+	ReasonAllCards      = "all_cards_revealed"
+)
+
+// incoming maps each client message type to its payload.
+var incoming = map[string]func() Payload{
+	MsgJoinTeam:       func() Payload { return &JoinTeam{} },
+	MsgSetRole:        func() Payload { return &SetRole{} },
+	MsgStartGame:      func() Payload { return &StartGame{} },
+	MsgGiveClue:       func() Payload { return &GiveClue{} },
+	MsgGuessCard:      func() Payload { return &GuessCard{} },
+	MsgEndGuessing:    func() Payload { return &EndGuessing{} },
+	MsgNewGame:        func() Payload { return &NewGame{} },
+	MsgUpdateSettings: func() Payload { return &UpdateSettings{} },
+	MsgChatSend:       func() Payload { return &ChatSend{} },
+	MsgChatHistory:    func() Payload { return &ChatHistory{} },
+	MsgResume:         func() Payload { return &Resume{} },
+}
+
+// incomingTypes maps each client payload type back to its message type.
+var incomingTypes = func() map[reflect.Type]string {
+	m := make(map[reflect.Type]string, len(incoming))
+	for msgType, newPayload := range incoming {
+		m[reflect.TypeOf(newPayload())] = msgType
+	}
+	return m
+}()
+
+// TypeOf returns the message type of a payload returned by Decode.
+func TypeOf(p Payload) string {
+	return incomingTypes[reflect.TypeOf(p)]
+}
+
+// outgoing maps each server message type to an example of its payload,
+// nil for messages without one.
+var outgoing = map[string]any{
+	MsgRoomState:    model.RoomState{},
+	MsgError:        Error{},
+	MsgChatMessage:  model.ChatMessage{},
+	MsgChatHistory:  ChatHistoryResult{},
+	MsgResync:       nil,
+	MsgClueGiven:    ClueGiven{},
+	MsgCardRevealed: CardRevealed{},
+	MsgTurnEnded:    TurnEnded{},
+	MsgGameFinished: GameFinished{},
+	MsgPlayerJoined: PlayerPresence{},
+	MsgPlayerLeft:   PlayerPresence{},
+
+	MsgServerRestarting: nil,
+	MsgAnnouncement:     Announcement{},
+}
+
+// DecodeServerPayload decodes the JSON payload of a server message into
+// its payload type, for relaying messages between server instances.
+func DecodeServerPayload(msgType string, data []byte) (any, error) {
+	example, ok := outgoing[msgType]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type: %q", msgType)
+	}
+	if example == nil {
+		return nil, nil
+	}
+	p := reflect.New(reflect.TypeOf(example))
+	if err := json.Unmarshal(data, p.Interface()); err != nil {
+		return nil, err
+	}
+	return p.Elem().Interface(), nil
+}
+
+// JoinTeam moves the player to a team, or out of any team with an empty team.
+type JoinTeam struct {
+	Team model.Team `json:"team"`
+	Role model.Role `json:"role,omitempty"`
+}
+
+func (p *JoinTeam) Validate() error {
+	if p.Team != model.TeamRed && p.Team != model.TeamBlue && p.Team != "" {
+		return errors.New("invalid team")
+	}
+	if p.Role != model.RoleSpymaster && p.Role != model.RoleOperative && p.Role != "" {
+		return errors.New("invalid role")
+	}
+	return nil
+}
+
+type SetRole struct {
+	Role model.Role `json:"role"`
+}
+
+func (p *SetRole) Validate() error {
+	if p.Role != model.RoleSpymaster && p.Role != model.RoleOperative {
+		return errors.New("invalid role")
+	}
+	return nil
+}
+
+type StartGame struct{}
+
+func (p *StartGame) Validate() error { return nil }
+
+type GiveClue struct {
+	Clue   string `json:"clue"`
+	Number int    `json:"number"`
+}
+
+func (p *GiveClue) Validate() error {
+	if p.Clue == "" {
+		return errors.New("clue cannot be empty")
+	}
+	if p.Number < 0 {
+		return errors.New("number must be >= 0")
+	}
+	return nil
+}
+
+type GuessCard struct {
+	CardID string `json:"card_id"`
+}
+
+func (p *GuessCard) Validate() error {
+	if p.CardID == "" {
+		return errors.New("card_id is required")
+	}
+	return nil
+}
+
+type EndGuessing struct{}
+
+func (p *EndGuessing) Validate() error { return nil }
+
+type NewGame struct{}
+
+func (p *NewGame) Validate() error { return nil }
+
+// UpdateSettings replaces the room settings. Their content is validated by
+// the game package when applied.
+type UpdateSettings struct {
+	Settings *model.RoomSettings `json:"settings"`
+}
+
+func (p *UpdateSettings) Validate() error {
+	if p.Settings == nil {
+		return errors.New("settings are required")
+	}
+	return nil
+}
+
+type ChatSend struct {
+	Channel model.ChatChannel `json:"channel"`
+	Text    string            `json:"text"`
+}
+
+func (p *ChatSend) Validate() error {
+	return validateChannel(p.Channel)
+}
+
+// ChatHistory asks for a page of messages older than Before, the newest
+// page when Before is zero.
+type ChatHistory struct {
+	Channel model.ChatChannel `json:"channel"`
+	Before  int64             `json:"before,omitempty"`
+	Limit   int               `json:"limit,omitempty"`
+}
+
+func (p *ChatHistory) Validate() error {
+	if p.Before < 0 || p.Limit < 0 {
+		return errors.New("before and limit must be >= 0")
+	}
+	return validateChannel(p.Channel)
+}
+
+// Resume asks for the messages published after LastSeq.
+type Resume struct {
+	LastSeq uint64 `json:"last_seq"`
+}
+
+func (p *Resume) Validate() error { return nil }
+
+func validateChannel(c model.ChatChannel) error {
+	if c != model.ChatChannelRoom && c != model.ChatChannelTeam {
+		return errors.New("invalid chat channel")
+	}
+	return nil
+}
+
+// Error reports a message the server could not handle. Code is set for
+// errors a client can act on.
+type Error struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+	// RetryAfterMs is how long a rate limited client should wait.
+	RetryAfterMs int64 `json:"retry_after_ms,omitempty"`
+}
+
+type ChatHistoryResult struct {
+	Channel  model.ChatChannel   `json:"channel"`
+	Messages []model.ChatMessage `json:"messages"`
+}
+
+type ClueGiven struct {
+	Player model.Player `json:"player"`
+	Team   model.Team   `json:"team"`
+	Clue   string       `json:"clue"`
+	Number int          `json:"number"`
+}
+
+// CardRevealed carries the revealed card with its type.
+type CardRevealed struct {
+	Player model.Player   `json:"player"`
+	Team   model.Team     `json:"team"`
+	Card   model.CardView `json:"card"`
+}
+
+// TurnEnded names the team whose turn it now is. Player is set when a
+// player ended the turn themselves.
+type TurnEnded struct {
+	Player *model.Player `json:"player,omitempty"`
+	Team   model.Team    `json:"team"`
+	Reason string        `json:"reason"`
+}
+
+type GameFinished struct {
+	Winner model.Team `json:"winner"`
+	Reason string     `json:"reason"`
+}
+
+type PlayerPresence struct {
+	Player model.Player `json:"player"`
+}
+
+type Announcement struct {
+	Message string `json:"message"`
+}
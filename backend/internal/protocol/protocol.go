@@ -0,0 +1,101 @@
+// Package protocol defines the messages exchanged over the room WebSocket.
+//
+// Every message is an envelope with a type and a type-specific payload.
+// The protocol version and encoding are negotiated through the WebSocket
+// subprotocol (codenames.v1, codenames.v1.msgpack, ...); a client that
+// offers none gets the current version in JSON.
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Version is the newest protocol version the server speaks.
+const Version = 1
+
+// Supported lists the protocol versions the server speaks, newest first.
+var Supported = []int{1}
+
+const subprotocolPrefix = "codenames.v"
+
+// Subprotocol returns the WebSocket subprotocol name of a protocol version
+// in an encoding.
+func Subprotocol(version int, enc Encoding) string {
+	return subprotocolPrefix + strconv.Itoa(version) + enc.suffix()
+}
+
+// Subprotocols returns the subprotocol names of all supported versions and
+// encodings, in the order the server prefers them.
+func Subprotocols() []string {
+	var names []string
+	for _, v := range Supported {
+		for _, enc := range Encodings {
+			names = append(names, Subprotocol(v, enc))
+		}
+	}
+	return names
+}
+
+// Negotiate picks the newest supported version among the subprotocols a
+// client offered, in the first encoding the client offered it in. A client
+// that offers none gets the current version in JSON.
+func Negotiate(offered []string) (int, Encoding, bool) {
+	if len(offered) == 0 {
+		return Version, EncodingJSON, true
+	}
+	for _, v := range Supported {
+		for _, name := range offered {
+			for _, enc := range Encodings {
+				if name == Subprotocol(v, enc) {
+					return v, enc, true
+				}
+			}
+		}
+	}
+	return 0, EncodingJSON, false
+}
+
+// Incoming is the envelope of a message from a client.
+type Incoming struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Outgoing is the envelope of a message to a client. Seq increases by one
+// with every message published in the room; a client only receives the
+// ones meant for it.
+type Outgoing struct {
+	Type    string `json:"type"`
+	Seq     uint64 `json:"seq"`
+	Payload any    `json:"payload,omitempty"`
+}
+
+// Payload is the payload of a message from a client.
+type Payload interface {
+	Validate() error
+}
+
+// Decode parses a client message, rejecting unknown types and fields, and
+// validates its payload.
+func Decode(data []byte, enc Encoding) (Payload, error) {
+	msgType, payload, err := enc.envelope(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message format: %w", err)
+	}
+	newPayload, ok := incoming[msgType]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type: %q", msgType)
+	}
+	p := newPayload()
+	if len(payload) > 0 {
+		if err := enc.decodeStrict(payload, p); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", msgType, err)
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", msgType, err)
+	}
+	return p, nil
+}
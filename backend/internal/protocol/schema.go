@@ -0,0 +1,170 @@
+package protocol
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codenames/internal/model"
+)
+
+// enums lists the values of the named string types used in messages.
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(model.Team("")):        {"red", "blue", ""},
+	reflect.TypeOf(model.Role("")):        {"spymaster", "operative", ""},
+	reflect.TypeOf(model.Phase("")):       {"lobby", "playing", "finished"},
+	reflect.TypeOf(model.Presence("")):    {"online", "reconnecting", "offline"},
+	reflect.TypeOf(model.CardType("")):    {"red", "blue", "neutral", "assassin", ""},
+	reflect.TypeOf(model.ChatChannel("")): {"room", "team"},
+	reflect.TypeOf(model.Variant("")):     {"classic"},
+	reflect.TypeOf(model.Permission("")):  {"anyone", "spymasters"},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema returns a JSON Schema describing every message of the current
+// protocol version, generated from the Go message types.
+var Schema = sync.OnceValue(func() map[string]any {
+	b := &schemaBuilder{defs: make(map[string]any)}
+
+	var client []any
+	for _, name := range sortedKeys(incoming) {
+		env := envelope(name, false)
+		props := env["properties"].(map[string]any)
+		if p := incoming[name](); isEmptyStruct(p) {
+			props["payload"] = map[string]any{"type": []string{"object", "null"}, "additionalProperties": false}
+		} else {
+			props["payload"] = b.schemaFor(reflect.TypeOf(p).Elem())
+			env["required"] = []string{"type", "payload"}
+		}
+		client = append(client, env)
+	}
+
+	var server []any
+	for _, name := range sortedKeys(outgoing) {
+		env := envelope(name, true)
+		if example := outgoing[name]; example != nil {
+			env["properties"].(map[string]any)["payload"] = b.schemaFor(reflect.TypeOf(example))
+			env["required"] = []string{"type", "seq", "payload"}
+		}
+		server = append(server, env)
+	}
+
+	b.defs["ClientMessage"] = map[string]any{"oneOf": client}
+	b.defs["ServerMessage"] = map[string]any{"oneOf": server}
+	return map[string]any{
+		"$schema":      "https://json-schema.org/draft/2020-12/schema",
+		"title":        "Codenames WebSocket protocol",
+		"version":      Version,
+		"subprotocols": Subprotocols(),
+		"$defs":        b.defs,
+		"oneOf": []any{
+			map[string]any{"$ref": "#/$defs/ClientMessage"},
+			map[string]any{"$ref": "#/$defs/ServerMessage"},
+		},
+	}
+})
+
+func envelope(msgType string, server bool) map[string]any {
+	props := map[string]any{
+		"type": map[string]any{"const": msgType},
+	}
+	required := []string{"type"}
+	if server {
+		props["seq"] = map[string]any{"type": "integer", "minimum": 0}
+		required = append(required, "seq")
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+type schemaBuilder struct {
+	defs map[string]any
+}
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]any {
+	if values, ok := enums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]any{"anyOf": []any{b.schemaFor(t.Elem()), map[string]any{"type": "null"}}}
+	case reflect.Struct:
+		if _, ok := b.defs[t.Name()]; !ok {
+			b.defs[t.Name()] = nil // reserve the name for recursive types
+			b.defs[t.Name()] = b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice:
+		// nil slices encode as null
+		return map[string]any{"type": []string{"array", "null"}, "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = b.schemaFor(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func isEmptyStruct(v any) bool {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,111 @@
+// Package ratelimit limits how often something may happen per key, such as
+// a client IP or a session, with a token bucket for each key.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Burst events at once, refilling at Burst events per Per.
+// The zero Limit allows everything.
+type Limit struct {
+	Burst int
+	Per   time.Duration
+}
+
+// ParseLimit parses a limit written as "<burst>/<duration>", e.g. "10/1m".
+// "off" and "" parse to the zero Limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" || s == "off" {
+		return Limit{}, nil
+	}
+	n, d, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want <burst>/<duration>", s)
+	}
+	burst, err := strconv.Atoi(n)
+	if err != nil || burst <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit burst %q", n)
+	}
+	per, err := time.ParseDuration(d)
+	if err != nil || per <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit duration %q", d)
+	}
+	return Limit{Burst: burst, Per: per}, nil
+}
+
+func (l Limit) String() string {
+	if !l.enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Burst, l.Per)
+}
+
+func (l Limit) enabled() bool {
+	return l.Burst > 0 && l.Per > 0
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter keeps one bucket per key. Buckets that have refilled are dropped,
+// so idle keys cost nothing.
+type Limiter struct {
+	limit Limit
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(limit Limit) *Limiter {
+	return &Limiter{limit: limit, now: time.Now, buckets: make(map[string]*bucket)}
+}
+
+// Allow takes a token from the key's bucket. When the bucket is empty it
+// returns false and how long until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || !l.limit.enabled() {
+		return true, 0
+	}
+	now := l.now()
+	interval := l.limit.Per / time.Duration(l.limit.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst)}
+		l.buckets[key] = b
+	} else {
+		b.tokens = min(float64(l.limit.Burst), b.tokens+float64(now.Sub(b.updated))/float64(interval))
+	}
+	b.updated = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(interval))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the full buckets, at most once per Per.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Per {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.limit.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
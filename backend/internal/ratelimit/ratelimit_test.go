@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "", want: Limit{}},
+		{in: "off", want: Limit{}},
+		{in: "10/1m", want: Limit{Burst: 10, Per: time.Minute}},
+		{in: "1/500ms", want: Limit{Burst: 1, Per: 500 * time.Millisecond}},
+		{in: "10", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "-1/1m", wantErr: true},
+		{in: "ten/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/-1m", wantErr: true},
+		{in: "10/minute", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit(%q) err = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+			if err == nil {
+				if again, err := ParseLimit(got.String()); err != nil || again != got {
+					t.Errorf("ParseLimit(%q) = %+v, %v, want it back", got.String(), again, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	// 2 at once, then one a second.
+	limit := Limit{Burst: 2, Per: 2 * time.Second}
+	steps := []struct {
+		after     time.Duration
+		key       string
+		want      bool
+		wantRetry time.Duration
+	}{
+		{0, "a", true, 0},
+		{0, "a", true, 0},
+		{0, "a", false, time.Second},
+		{0, "b", true, 0},
+		{250 * time.Millisecond, "a", false, 750 * time.Millisecond},
+		{750 * time.Millisecond, "a", true, 0},
+		{0, "a", false, time.Second},
+		{10 * time.Second, "a", true, 0},
+		{0, "a", true, 0},
+		{0, "a", false, time.Second},
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	l := New(limit)
+	l.now = func() time.Time { return now }
+	for i, s := range steps {
+		now = now.Add(s.after)
+		ok, retry := l.Allow(s.key)
+		if ok != s.want || retry != s.wantRetry {
+			t.Errorf("step %d: Allow(%q) at %v = %v, %v, want %v, %v", i, s.key, now.Sub(start), ok, retry, s.want, s.wantRetry)
+		}
+	}
+	if _, ok := l.buckets["b"]; ok {
+		t.Error("bucket of idle key b wasn't dropped")
+	}
+}
+
+func TestLimiterOff(t *testing.T) {
+	tests := []struct {
+		name string
+		l    *Limiter
+	}{
+		{"nil", nil},
+		{"zero limit", New(Limit{})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				if ok, retry := tt.l.Allow("a"); !ok || retry != 0 {
+					t.Fatalf("Allow = %v, %v, want true, 0", ok, retry)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+// Package record defines the portable format of a played game: a JSON
+// document holding the board with its key, the players, every move in the
+// order it was played and the result. The server exports finished games in
+// it and imports records made by any tool, after replaying their moves
+// against the rules.
+//
+// A record looks like:
+//
+//	{
+//	  "format": "codenames.game",
+//	  "version": 1,
+//	  "first_team": "red",
+//	  "board": [{"position": 0, "word": "АГЕНТ", "type": "red"}, ...],
+//	  "players": [{"name": "Аня", "team": "red", "role": "spymaster"}, ...],
+//	  "moves": [
+//	    {"kind": "clue", "team": "red", "player": "Аня", "clue": "ШПИОН", "number": 2},
+//	    {"kind": "guess", "team": "red", "player": "Боря", "position": 0},
+//	    {"kind": "end_guessing", "team": "red", "player": "Боря"},
+//	    ...
+//	  ],
+//	  "result": {"winner": "red", "reason": "all_cards_revealed"}
+//	}
+//
+// Board positions run from 0, row by row. Moves alternate between teams as
+// in a game: a clue, then guesses until the team misses, runs out of
+// guesses or ends guessing. A finish move is a game ended by an operator.
+// The result must be the one the moves lead to; a game whose moves don't
+// finish it was abandoned and has no winner.
+//
+// A record exported for players who may not see the key is redacted: the
+// cards no guess revealed have no type. Redacted records can't be imported.
+//
+// The format is versioned by Version. Readers reject records of a version
+// newer than theirs and ignore fields they don't know, so adding optional
+// fields doesn't bump it. Schema returns the JSON Schema of the current
+// version.
+package record
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"codenames/internal/game"
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+)
+
+const (
+	// Format identifies a document as a game record.
+	Format = "codenames.game"
+	// Version is the newest version of the format.
+	Version = 1
+)
+
+// ReasonAbandoned is the result of a game left before it finished.
+const ReasonAbandoned = "abandoned"
+
+var (
+	// ErrInProgress is returned for a game still being played, whose key
+	// must stay secret.
+	ErrInProgress = errors.New("game is still being played")
+	// ErrNoHistory is returned for a game whose moves weren't recorded or
+	// whose board was archived.
+	ErrNoHistory = errors.New("game history is not available")
+)
+
+type Record struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	// Redacted is set when the types of unrevealed cards were removed.
+	Redacted bool `json:"redacted,omitempty"`
+	// Source is where the game was played, for information only.
+	Source    *Source            `json:"source,omitempty"`
+	FirstTeam model.Team         `json:"first_team"`
+	Board     []Card             `json:"board"`
+	Players   []model.GamePlayer `json:"players"`
+	Moves     []Move             `json:"moves"`
+	Result    Result             `json:"result"`
+}
+
+type Source struct {
+	GameID     string    `json:"game_id"`
+	RoomID     string    `json:"room_id"`
+	StartedAt  time.Time `json:"started_at"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+type Card struct {
+	Position int            `json:"position"`
+	Word     string         `json:"word"`
+	Type     model.CardType `json:"type,omitempty"`
+}
+
+// Move is a model.Move with only the fields of its kind: clue and number
+// for a clue, position for a guess, winner for a finish.
+type Move struct {
+	Kind     model.MoveKind `json:"kind"`
+	Team     model.Team     `json:"team"`
+	Player   string         `json:"player,omitempty"`
+	Clue     string         `json:"clue,omitempty"`
+	Number   *int           `json:"number,omitempty"`
+	Position *int           `json:"position,omitempty"`
+	Winner   model.Team     `json:"winner,omitempty"`
+	At       *time.Time     `json:"at,omitempty"`
+}
+
+// Result is the winner, empty for none, and why the game ended: one of
+// protocol.ReasonAssassin, ReasonAllCards, ReasonEndedByAdmin or
+// ReasonAbandoned.
+type Result struct {
+	Winner model.Team `json:"winner"`
+	Reason string     `json:"reason"`
+}
+
+// New returns the record of a game that is over.
+func New(g model.Game, cards []model.Card, history model.GameHistory, exportedAt time.Time) (Record, error) {
+	if g.Phase == model.PhasePlaying {
+		return Record{}, ErrInProgress
+	}
+	if len(cards) == 0 || history.FirstTeam == "" {
+		return Record{}, ErrNoHistory
+	}
+
+	r := Record{
+		Format:  Format,
+		Version: Version,
+		Source: &Source{
+			GameID:     g.ID,
+			RoomID:     g.RoomID,
+			StartedAt:  history.StartedAt.UTC(),
+			ExportedAt: exportedAt.UTC(),
+		},
+		FirstTeam: history.FirstTeam,
+		Board:     make([]Card, len(cards)),
+		Players:   withoutIDs(history.Players),
+		Moves:     make([]Move, len(history.Moves)),
+	}
+	for i, c := range cards {
+		r.Board[i] = Card{Position: c.Position, Word: c.Word, Type: c.CardType}
+	}
+	for i, m := range history.Moves {
+		r.Moves[i] = moveOf(m)
+	}
+	// The result is what the moves lead to, so an exported record imports.
+	_, _, result, err := r.replay()
+	if err != nil {
+		return Record{}, fmt.Errorf("replay game %s: %w", g.ID, err)
+	}
+	r.Result = result
+	return r, nil
+}
+
+// withoutIDs returns the players without the IDs they had in their room,
+// which mean nothing in another.
+func withoutIDs(players []model.GamePlayer) []model.GamePlayer {
+	out := make([]model.GamePlayer, len(players))
+	for i, p := range players {
+		p.PlayerID = ""
+		out[i] = p
+	}
+	return out
+}
+
+func moveOf(m model.Move) Move {
+	rm := Move{Kind: m.Kind, Team: m.Team, Player: m.Player}
+	switch m.Kind {
+	case model.MoveClue:
+		rm.Clue, rm.Number = m.Clue, &m.Number
+	case model.MoveGuess:
+		rm.Position = &m.Position
+	case model.MoveFinish:
+		rm.Winner = m.Winner
+	}
+	if !m.CreatedAt.IsZero() {
+		at := m.CreatedAt.UTC()
+		rm.At = &at
+	}
+	return rm
+}
+
+// Redact removes the types of the cards no guess revealed.
+func (r *Record) Redact() {
+	revealed := make(map[int]bool)
+	for _, m := range r.Moves {
+		if m.Kind == model.MoveGuess && m.Position != nil {
+			revealed[*m.Position] = true
+		}
+	}
+	for i, c := range r.Board {
+		if !revealed[c.Position] {
+			r.Board[i].Type = ""
+		}
+	}
+	r.Redacted = true
+}
+
+// Decode reads a record and checks its format and version.
+func Decode(r io.Reader) (Record, error) {
+	var rec Record
+	if err := json.NewDecoder(r).Decode(&rec); err != nil {
+		return Record{}, fmt.Errorf("decode record: %w", err)
+	}
+	if rec.Format != Format {
+		return Record{}, fmt.Errorf("not a game record: format %q, want %q", rec.Format, Format)
+	}
+	if rec.Version < 1 || rec.Version > Version {
+		return Record{}, fmt.Errorf("unsupported record version %d, want at most %d", rec.Version, Version)
+	}
+	return rec, nil
+}
+
+// Game checks the record and returns the game, board and history it
+// describes, in the state its moves lead to. An abandoned game comes back
+// finished without a winner.
+func (r Record) Game() (model.Game, []model.Card, model.GameHistory, error) {
+	if r.Redacted {
+		return model.Game{}, nil, model.GameHistory{}, errors.New("record is redacted, its key is missing")
+	}
+	for _, p := range r.Players {
+		if p.Name == "" {
+			return model.Game{}, nil, model.GameHistory{}, errors.New("player without a name")
+		}
+		if p.Team != model.TeamRed && p.Team != model.TeamBlue {
+			return model.Game{}, nil, model.GameHistory{}, fmt.Errorf("player %q has invalid team %q", p.Name, p.Team)
+		}
+		if p.Role != model.RoleSpymaster && p.Role != model.RoleOperative {
+			return model.Game{}, nil, model.GameHistory{}, fmt.Errorf("player %q has invalid role %q", p.Name, p.Role)
+		}
+	}
+
+	g, cards, result, err := r.replay()
+	if err != nil {
+		return model.Game{}, nil, model.GameHistory{}, err
+	}
+	if r.Result != result {
+		return model.Game{}, nil, model.GameHistory{}, fmt.Errorf("result %q (%s) doesn't match the moves, which lead to %q (%s)",
+			r.Result.Winner, r.Result.Reason, result.Winner, result.Reason)
+	}
+
+	history := model.GameHistory{FirstTeam: r.FirstTeam, Players: withoutIDs(r.Players), Moves: make([]model.Move, len(r.Moves))}
+	for i, m := range r.Moves {
+		history.Moves[i], _ = m.model()
+	}
+	if g.Phase == model.PhasePlaying {
+		g.Phase = model.PhaseFinished
+		g.CurrentClue, g.CurrentNumber, g.GuessesLeft = "", 0, 0
+	}
+	return g, cards, history, nil
+}
+
+// replay plays the record's moves on its board.
+func (r Record) replay() (model.Game, []model.Card, Result, error) {
+	cards := make([]model.Card, len(r.Board))
+	for i, c := range r.Board {
+		cards[i] = model.Card{Position: c.Position, Word: c.Word, CardType: c.Type}
+	}
+	if err := game.ValidateBoard(cards); err != nil {
+		return model.Game{}, nil, Result{}, fmt.Errorf("invalid board: %w", err)
+	}
+	moves := make([]model.Move, len(r.Moves))
+	for i, m := range r.Moves {
+		mm, err := m.model()
+		if err != nil {
+			return model.Game{}, nil, Result{}, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		moves[i] = mm
+	}
+	g, err := game.Replay(r.FirstTeam, cards, moves)
+	if err != nil {
+		return model.Game{}, nil, Result{}, fmt.Errorf("invalid moves: %w", err)
+	}
+
+	result := Result{Winner: g.Winner, Reason: ReasonAbandoned}
+	if g.Phase == model.PhaseFinished {
+		last := moves[len(moves)-1]
+		switch {
+		case last.Kind == model.MoveFinish:
+			result.Reason = protocol.ReasonEndedByAdmin
+		case cards[cardAt(cards, last.Position)].CardType == model.CardTypeAssassin:
+			result.Reason = protocol.ReasonAssassin
+		default:
+			result.Reason = protocol.ReasonAllCards
+		}
+	}
+	return g, cards, result, nil
+}
+
+func cardAt(cards []model.Card, position int) int {
+	for i, c := range cards {
+		if c.Position == position {
+			return i
+		}
+	}
+	return -1
+}
+
+// model converts the move, checking it has the fields of its kind.
+func (m Move) model() (model.Move, error) {
+	mm := model.Move{Kind: m.Kind, Team: m.Team, Player: m.Player, Winner: m.Winner}
+	if m.At != nil {
+		mm.CreatedAt = *m.At
+	}
+	switch m.Kind {
+	case model.MoveClue:
+		if m.Number == nil {
+			return mm, errors.New("clue without a number")
+		}
+		mm.Clue, mm.Number = m.Clue, *m.Number
+	case model.MoveGuess:
+		if m.Position == nil {
+			return mm, errors.New("guess without a position")
+		}
+		mm.Position = *m.Position
+	}
+	return mm, nil
+}
+
+//go:embed schema.json
+var schema []byte
+
+// Schema returns the JSON Schema of the current version of the format.
+func Schema() []byte {
+	return schema
+}
@@ -0,0 +1,217 @@
+package record
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"codenames/internal/model"
+	"codenames/internal/protocol"
+)
+
+// testBoard returns a 16 card board: red at 0-3, blue at 4-6, the assassin
+// at 7 and neutral cards after.
+func testBoard() []Card {
+	board := make([]Card, 16)
+	for i := range board {
+		board[i] = Card{Position: i, Word: "W" + string(rune('A'+i)), Type: model.CardTypeNeutral}
+		switch {
+		case i < 4:
+			board[i].Type = model.CardTypeRed
+		case i < 7:
+			board[i].Type = model.CardTypeBlue
+		case i == 7:
+			board[i].Type = model.CardTypeAssassin
+		}
+	}
+	return board
+}
+
+func intp(n int) *int { return &n }
+
+// testRecord is a game red lost to the assassin.
+func testRecord() Record {
+	return Record{
+		Format:    Format,
+		Version:   Version,
+		FirstTeam: model.TeamRed,
+		Board:     testBoard(),
+		Players: []model.GamePlayer{
+			{Name: "Аня", Team: model.TeamRed, Role: model.RoleSpymaster},
+			{Name: "Боря", Team: model.TeamRed, Role: model.RoleOperative},
+		},
+		Moves: []Move{
+			{Kind: model.MoveClue, Team: model.TeamRed, Player: "Аня", Clue: "ШПИОН", Number: intp(2)},
+			{Kind: model.MoveGuess, Team: model.TeamRed, Player: "Боря", Position: intp(0)},
+			{Kind: model.MoveGuess, Team: model.TeamRed, Player: "Боря", Position: intp(7)},
+		},
+		Result: Result{Winner: model.TeamBlue, Reason: protocol.ReasonAssassin},
+	}
+}
+
+func TestGame(t *testing.T) {
+	tests := []struct {
+		name       string
+		edit       func(r *Record)
+		wantWinner model.Team
+		wantErr    string
+	}{
+		{name: "valid", wantWinner: model.TeamBlue},
+		{
+			name: "ended by an operator",
+			edit: func(r *Record) {
+				r.Moves = append(r.Moves[:2], Move{Kind: model.MoveFinish, Team: model.TeamRed, Winner: model.TeamRed})
+				r.Result = Result{Winner: model.TeamRed, Reason: protocol.ReasonEndedByAdmin}
+			},
+			wantWinner: model.TeamRed,
+		},
+		{
+			name: "abandoned",
+			edit: func(r *Record) {
+				r.Moves = r.Moves[:2]
+				r.Result = Result{Reason: ReasonAbandoned}
+			},
+		},
+		{
+			name:    "result not matching the moves",
+			edit:    func(r *Record) { r.Result = Result{Winner: model.TeamRed, Reason: protocol.ReasonAllCards} },
+			wantErr: "doesn't match the moves",
+		},
+		{
+			name:    "illegal move",
+			edit:    func(r *Record) { r.Moves[1].Team = model.TeamBlue },
+			wantErr: "invalid moves: move 2: not blue's turn",
+		},
+		{
+			name:    "guess without a position",
+			edit:    func(r *Record) { r.Moves[1].Position = nil },
+			wantErr: "move 2: guess without a position",
+		},
+		{
+			name:    "clue without a number",
+			edit:    func(r *Record) { r.Moves[0].Number = nil },
+			wantErr: "move 1: clue without a number",
+		},
+		{
+			name:    "invalid board",
+			edit:    func(r *Record) { r.Board[3].Position = 0 },
+			wantErr: "invalid board: invalid or duplicate card position 0",
+		},
+		{
+			name:    "player without a name",
+			edit:    func(r *Record) { r.Players[0].Name = "" },
+			wantErr: "player without a name",
+		},
+		{
+			name:    "player with an invalid role",
+			edit:    func(r *Record) { r.Players[1].Role = "captain" },
+			wantErr: `player "Боря" has invalid role "captain"`,
+		},
+		{
+			name:    "redacted",
+			edit:    func(r *Record) { r.Redact() },
+			wantErr: "record is redacted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := testRecord()
+			if tt.edit != nil {
+				tt.edit(&rec)
+			}
+			g, cards, history, err := rec.Game()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Game err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Game: %v", err)
+			}
+			if g.Phase != model.PhaseFinished || g.Winner != tt.wantWinner {
+				t.Errorf("game is %s won by %q, want finished won by %q", g.Phase, g.Winner, tt.wantWinner)
+			}
+			if len(cards) != len(rec.Board) || !cards[0].Revealed {
+				t.Errorf("cards don't show the guesses: %+v", cards[0])
+			}
+			if len(history.Moves) != len(rec.Moves) || history.FirstTeam != rec.FirstTeam {
+				t.Errorf("history = %+v, want the record's moves", history)
+			}
+		})
+	}
+}
+
+func TestNewRoundTrip(t *testing.T) {
+	rec := testRecord()
+	g, cards, history, err := rec.Game()
+	if err != nil {
+		t.Fatalf("Game: %v", err)
+	}
+	history.Players[0].PlayerID = "p1"
+	exported, err := New(g, cards, history, time.Now())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if exported.Players[0].PlayerID != "" {
+		t.Errorf("exported player has the room's ID %q", exported.Players[0].PlayerID)
+	}
+	if exported.Result != rec.Result || len(exported.Board) != len(rec.Board) || len(exported.Moves) != len(rec.Moves) {
+		t.Errorf("New = %+v, want the record back", exported)
+	}
+	if _, _, _, err := exported.Game(); err != nil {
+		t.Errorf("exported record doesn't import: %v", err)
+	}
+
+	g.Phase = model.PhasePlaying
+	if _, err := New(g, cards, history, time.Now()); !errors.Is(err, ErrInProgress) {
+		t.Errorf("New of a game in progress err = %v, want ErrInProgress", err)
+	}
+	if _, err := New(model.Game{Phase: model.PhaseFinished}, nil, model.GameHistory{}, time.Now()); !errors.Is(err, ErrNoHistory) {
+		t.Errorf("New without history err = %v, want ErrNoHistory", err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	rec := testRecord()
+	rec.Redact()
+	if !rec.Redacted {
+		t.Error("record isn't marked redacted")
+	}
+	for _, c := range rec.Board {
+		revealed := c.Position == 0 || c.Position == 7
+		if revealed != (c.Type != "") {
+			t.Errorf("card %d has type %q after redacting, want it only for guessed cards", c.Position, c.Type)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{name: "valid", in: `{"format": "codenames.game", "version": 1}`},
+		{name: "unknown fields", in: `{"format": "codenames.game", "version": 1, "comment": "x"}`},
+		{name: "not json", in: `format`, wantErr: "decode record"},
+		{name: "other format", in: `{"format": "chess.game", "version": 1}`, wantErr: "not a game record"},
+		{name: "newer version", in: `{"format": "codenames.game", "version": 2}`, wantErr: "unsupported record version 2"},
+		{name: "no version", in: `{"format": "codenames.game"}`, wantErr: "unsupported record version 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.in))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Decode: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Decode err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// goBold is the font of both formats: text is measured with it for the
+// layout and PDFs embed it. Its glyphs cover Latin and Cyrillic words.
+var goBold = mustParse(gobold.TTF)
+
+func mustParse(ttf []byte) *sfnt.Font {
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		panic("render: parse font: " + err.Error())
+	}
+	return f
+}
+
+// unitsPerEm gives metrics in font units, the grid the font is drawn on.
+func unitsPerEm() fixed.Int26_6 {
+	return fixed.I(int(goBold.UnitsPerEm()))
+}
+
+// glyph returns the glyph of r, or the font's missing glyph.
+func glyph(r rune) sfnt.GlyphIndex {
+	g, err := goBold.GlyphIndex(nil, r)
+	if err != nil {
+		return 0
+	}
+	return g
+}
+
+// advance returns how far a glyph moves the pen, in ems.
+func advance(g sfnt.GlyphIndex) float64 {
+	a, err := goBold.GlyphAdvance(nil, g, unitsPerEm(), font.HintingNone)
+	if err != nil {
+		return 0
+	}
+	return float64(a) / float64(unitsPerEm())
+}
+
+// textWidth returns the width of s at a size, in points.
+func textWidth(s string, size float64) float64 {
+	w := 0.0
+	for _, r := range s {
+		w += advance(glyph(r))
+	}
+	return w * size
+}
@@ -0,0 +1,183 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"codenames/internal/model"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/sfnt"
+)
+
+// PDF writes a sheet of a board as a one page PDF with Go Bold embedded,
+// so it prints the same everywhere.
+func PDF(w io.Writer, cards []model.Card, firstTeam model.Team, opts Options) error {
+	c := &pdfCanvas{glyphs: make(map[sfnt.GlyphIndex]rune)}
+	draw(c, cards, firstTeam, opts)
+
+	doc, err := c.document()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(doc); err != nil {
+		return fmt.Errorf("write pdf: %w", err)
+	}
+	return nil
+}
+
+// pdfCanvas collects the page's content stream and the glyphs it uses.
+// PDF coordinates run up from the bottom of the page.
+type pdfCanvas struct {
+	content bytes.Buffer
+	glyphs  map[sfnt.GlyphIndex]rune
+}
+
+func (c *pdfCanvas) fillRect(x, y, w, h float64, col color) {
+	fmt.Fprintf(&c.content, "%s rg %.2f %.2f %.2f %.2f re f\n", col.pdf(), x, pageHeight-y-h, w, h)
+}
+
+func (c *pdfCanvas) strokeRect(x, y, w, h, width float64, col color) {
+	fmt.Fprintf(&c.content, "%s RG %.2f w %.2f %.2f %.2f %.2f re S\n", col.pdf(), width, x, pageHeight-y-h, w, h)
+}
+
+func (c *pdfCanvas) line(x1, y1, x2, y2, width float64, col color) {
+	fmt.Fprintf(&c.content, "%s RG %.2f w %.2f %.2f m %.2f %.2f l S\n", col.pdf(), width, x1, pageHeight-y1, x2, pageHeight-y2)
+}
+
+func (c *pdfCanvas) circle(cx, cy, r float64, col color) {
+	// Four Bézier curves, each a quarter of the circle.
+	const k = 0.5523
+	y := pageHeight - cy
+	fmt.Fprintf(&c.content, "%s rg %.2f %.2f m\n", col.pdf(), cx+r, y)
+	fmt.Fprintf(&c.content, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx+r, y+k*r, cx+k*r, y+r, cx, y+r)
+	fmt.Fprintf(&c.content, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx-k*r, y+r, cx-r, y+k*r, cx-r, y)
+	fmt.Fprintf(&c.content, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx-r, y-k*r, cx-k*r, y-r, cx, y-r)
+	fmt.Fprintf(&c.content, "%.2f %.2f %.2f %.2f %.2f %.2f c f\n", cx+k*r, y-r, cx+r, y-k*r, cx+r, y)
+}
+
+// text shows glyph indices, which the font's Identity-H encoding maps to
+// its glyphs directly.
+func (c *pdfCanvas) text(x, y, size float64, s string, col color) {
+	var hex strings.Builder
+	for _, r := range s {
+		g := glyph(r)
+		c.glyphs[g] = r
+		fmt.Fprintf(&hex, "%04X", uint16(g))
+	}
+	fmt.Fprintf(&c.content, "BT %s rg /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n",
+		col.pdf(), size, x-textWidth(s, size)/2, pageHeight-y, hex.String())
+}
+
+func (c color) pdf() string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.r)/255, float64(c.g)/255, float64(c.b)/255)
+}
+
+// document assembles the page and its font into a PDF file.
+func (c *pdfCanvas) document() ([]byte, error) {
+	content, err := deflate(c.content.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	fontFile, err := deflate(gobold.TTF)
+	if err != nil {
+		return nil, err
+	}
+	toUnicode, err := deflate(c.toUnicode())
+	if err != nil {
+		return nil, err
+	}
+
+	// Font metrics are in thousandths of an em.
+	em := func(v float64) int { return int(v * 1000) }
+	scale := float64(unitsPerEm())
+	metrics, err := goBold.Metrics(nil, unitsPerEm(), font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("font metrics: %w", err)
+	}
+	bounds, err := goBold.Bounds(nil, unitsPerEm(), font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("font bounds: %w", err)
+	}
+	var widths strings.Builder
+	for _, g := range slices.Sorted(maps.Keys(c.glyphs)) {
+		fmt.Fprintf(&widths, "%d [%d] ", g, em(advance(g)))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>", pageWidth, pageHeight),
+		stream("", content),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /GoBold /Encoding /Identity-H /DescendantFonts [6 0 R] /ToUnicode 9 0 R >>",
+		fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /GoBold /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor 7 0 R /CIDToGIDMap /Identity /W [%s] >>", widths.String()),
+		fmt.Sprintf("<< /Type /FontDescriptor /FontName /GoBold /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 120 /FontFile2 8 0 R >>",
+			em(float64(bounds.Min.X)/scale), em(float64(-bounds.Max.Y)/scale), em(float64(bounds.Max.X)/scale), em(float64(-bounds.Min.Y)/scale),
+			em(float64(metrics.Ascent)/scale), em(float64(-metrics.Descent)/scale), em(float64(metrics.CapHeight)/scale)),
+		stream(fmt.Sprintf("/Length1 %d", len(gobold.TTF)), fontFile),
+		stream("", toUnicode),
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return doc.Bytes(), nil
+}
+
+// toUnicode maps the glyphs shown back to text, for copying and searching.
+func (c *pdfCanvas) toUnicode() []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// A bfchar block holds at most 100 mappings.
+	for chunk := range slices.Chunk(slices.Sorted(maps.Keys(c.glyphs)), 100) {
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&b, "<%04X> <", uint16(g))
+			for _, u := range utf16.Encode([]rune{c.glyphs[g]}) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// stream returns a stream object of deflated data with extra dictionary
+// entries.
+func stream(extra string, data []byte) string {
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode %s>>\nstream\n%s\nendstream", len(data), extra, data)
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("deflate: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("deflate: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,226 @@
+// Package render draws the board of a game and its spymaster key card as
+// printable A4 pages, in SVG or PDF, for groups that play at a table with
+// boards made by the app.
+//
+// Both formats are drawn from the same layout through a small canvas, so
+// they look alike. The key card shows the card types in the app's colours
+// and, with Options.Patterns, in patterns that don't rely on telling the
+// colours apart: stripes for red, dots for blue, a cross for the assassin
+// and none for neutral cards.
+package render
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"codenames/internal/model"
+)
+
+type Sheet string
+
+const (
+	// SheetBoard is the words as they are laid out on the table.
+	SheetBoard Sheet = "board"
+	// SheetKey is the spymaster key card: the type of every card and the
+	// team that starts.
+	SheetKey Sheet = "key"
+)
+
+type Options struct {
+	Sheet Sheet
+	// Patterns marks card types with patterns as well as colours, for
+	// colour-blind players.
+	Patterns bool
+}
+
+// ParseSheet returns the sheet of a name.
+func ParseSheet(name string) (Sheet, error) {
+	switch s := Sheet(name); s {
+	case SheetBoard, SheetKey:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown sheet %q, want board or key", name)
+}
+
+// A4 landscape in points, the unit of both formats.
+const (
+	pageWidth  = 842.0
+	pageHeight = 595.0
+	margin     = 36.0
+	gap        = 8.0
+	// columns matches the board as the app lays it out.
+	columns = 5
+)
+
+type color struct{ r, g, b uint8 }
+
+// The app's colours, see the frontend's Card and GameOverBanner.
+var (
+	colorRed      = color{0xe7, 0x4c, 0x3c}
+	colorBlue     = color{0x34, 0x98, 0xdb}
+	colorNeutral  = color{0xbd, 0xc3, 0xc7}
+	colorAssassin = color{0x2c, 0x3e, 0x50}
+	colorRedTeam  = color{0xd3, 0x2f, 0x2f}
+	colorBlueTeam = color{0x19, 0x76, 0xd2}
+	colorText     = color{0x33, 0x33, 0x33}
+	colorBorder   = color{0xdd, 0xdd, 0xdd}
+	colorWhite    = color{0xff, 0xff, 0xff}
+)
+
+// canvas is what a format draws. Coordinates are in points from the top
+// left corner of the page.
+type canvas interface {
+	fillRect(x, y, w, h float64, c color)
+	strokeRect(x, y, w, h, width float64, c color)
+	line(x1, y1, x2, y2, width float64, c color)
+	circle(cx, cy, r float64, c color)
+	// text draws s centred on x with its baseline at y.
+	text(x, y, size float64, s string, c color)
+}
+
+var typeNames = []struct {
+	t    model.CardType
+	name string
+}{
+	{model.CardTypeRed, "Красные"},
+	{model.CardTypeBlue, "Синие"},
+	{model.CardTypeNeutral, "Нейтральные"},
+	{model.CardTypeAssassin, "Убийца"},
+}
+
+// draw lays out the sheet of a board on the page.
+func draw(c canvas, cards []model.Card, firstTeam model.Team, opts Options) {
+	cards = slices.Clone(cards)
+	slices.SortFunc(cards, func(a, b model.Card) int { return a.Position - b.Position })
+
+	title := "Кодовые имена"
+	if opts.Sheet == SheetKey {
+		title = "Карта спаймастера"
+		switch firstTeam {
+		case model.TeamRed:
+			title += " — начинают красные"
+		case model.TeamBlue:
+			title += " — начинают синие"
+		}
+	}
+	c.text(pageWidth/2, margin+18, 20, title, colorText)
+
+	top, bottom := margin+40, pageHeight-margin
+	if opts.Sheet == SheetKey {
+		bottom -= 36
+	}
+	rows := (len(cards) + columns - 1) / columns
+	w := (pageWidth - 2*margin - gap*(columns-1)) / columns
+	h := (bottom - top - gap*float64(rows-1)) / float64(rows)
+	if opts.Sheet == SheetKey {
+		w, h = min(w, h), min(w, h)
+	} else {
+		// The proportions of the cards in the app.
+		w, h = min(w, h*1.4), min(h, w/1.4)
+	}
+	gridW := w*columns + gap*(columns-1)
+	gridH := h*float64(rows) + gap*float64(rows-1)
+	left := (pageWidth - gridW) / 2
+
+	if opts.Sheet == SheetKey {
+		// The frame shows the team that starts, as on the cards of the
+		// board game.
+		if frame, ok := teamColor(firstTeam); ok {
+			c.strokeRect(left-gap, top-gap, gridW+2*gap, gridH+2*gap, 6, frame)
+		}
+	}
+	for i, card := range cards {
+		x := left + float64(i%columns)*(w+gap)
+		y := top + float64(i/columns)*(h+gap)
+		word := strings.ToUpper(card.Word)
+		if opts.Sheet == SheetBoard {
+			c.strokeRect(x, y, w, h, 1.5, colorBorder)
+			size := fitText(word, w-12, 22)
+			c.text(x+w/2, y+h/2+size*0.35, size, word, colorText)
+			continue
+		}
+		// The word goes on a band under the type, readable on any colour.
+		size := fitText(word, w-6, 10)
+		band := size + 8
+		drawType(c, x, y, w, h-band, card.CardType, opts.Patterns)
+		c.strokeRect(x, y, w, h, 1, colorBorder)
+		c.text(x+w/2, y+h-band/2+size*0.35, size, word, colorText)
+	}
+	if opts.Sheet == SheetKey {
+		drawLegend(c, pageHeight-margin-18, opts.Patterns)
+	}
+}
+
+// drawLegend names the colours and patterns in a row centred on the page.
+func drawLegend(c canvas, y float64, patterns bool) {
+	const swatch, size, spacing = 18.0, 12.0, 24.0
+	total := 0.0
+	for _, tn := range typeNames {
+		total += swatch + 6 + textWidth(tn.name, size) + spacing
+	}
+	x := (pageWidth - total + spacing) / 2
+	for _, tn := range typeNames {
+		drawType(c, x, y, swatch, swatch, tn.t, patterns)
+		c.strokeRect(x, y, swatch, swatch, 1, colorBorder)
+		width := textWidth(tn.name, size)
+		c.text(x+swatch+6+width/2, y+swatch/2+size*0.35, size, tn.name, colorText)
+		x += swatch + 6 + width + spacing
+	}
+}
+
+// drawType fills a rectangle with the colour of a card type and, with
+// patterns, its pattern.
+func drawType(c canvas, x, y, w, h float64, t model.CardType, patterns bool) {
+	fill := map[model.CardType]color{
+		model.CardTypeRed:      colorRed,
+		model.CardTypeBlue:     colorBlue,
+		model.CardTypeNeutral:  colorNeutral,
+		model.CardTypeAssassin: colorAssassin,
+	}[t]
+	c.fillRect(x, y, w, h, fill)
+	if !patterns {
+		return
+	}
+	step := max(6, min(12, min(w, h)/4))
+	switch t {
+	case model.CardTypeRed:
+		// Diagonal stripes: the segments of u+v = d within the rectangle,
+		// with u running right from its left and v up from its bottom.
+		for d := step / 2; d < w+h; d += step {
+			u1, u2 := max(0, d-h), min(w, d)
+			c.line(x+u1, y+h-(d-u1), x+u2, y+h-(d-u2), step/4, colorWhite)
+		}
+	case model.CardTypeBlue:
+		cols, rows := int(w/step), int(h/step)
+		dx, dy := w/float64(cols), h/float64(rows)
+		for i := range cols {
+			for j := range rows {
+				c.circle(x+dx*(float64(i)+0.5), y+dy*(float64(j)+0.5), step/5, colorWhite)
+			}
+		}
+	case model.CardTypeAssassin:
+		inset := min(w, h) / 6
+		width := max(2, min(w, h)/12)
+		c.line(x+inset, y+inset, x+w-inset, y+h-inset, width, colorWhite)
+		c.line(x+w-inset, y+inset, x+inset, y+h-inset, width, colorWhite)
+	}
+}
+
+func teamColor(t model.Team) (color, bool) {
+	switch t {
+	case model.TeamRed:
+		return colorRedTeam, true
+	case model.TeamBlue:
+		return colorBlueTeam, true
+	}
+	return color{}, false
+}
+
+// fitText returns the largest size up to maxSize at which s fits in width.
+func fitText(s string, width, maxSize float64) float64 {
+	if w := textWidth(s, maxSize); w > width {
+		return maxSize * width / w
+	}
+	return maxSize
+}
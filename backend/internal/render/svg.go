@@ -0,0 +1,54 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+
+	"codenames/internal/model"
+)
+
+// SVG writes a sheet of a board as an SVG page. Text is set in Go Bold,
+// falling back to the viewer's sans-serif font.
+func SVG(w io.Writer, cards []model.Card, firstTeam model.Team, opts Options) error {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.buf, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="297mm" height="210mm" viewBox="0 0 %g %g" font-family="Go, Arial, sans-serif" font-weight="bold">
+<rect width="%g" height="%g" fill="#fff"/>
+`, pageWidth, pageHeight, pageWidth, pageHeight)
+	draw(c, cards, firstTeam, opts)
+	c.buf.WriteString("</svg>\n")
+	if _, err := w.Write(c.buf.Bytes()); err != nil {
+		return fmt.Errorf("write svg: %w", err)
+	}
+	return nil
+}
+
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func (c *svgCanvas) fillRect(x, y, w, h float64, col color) {
+	fmt.Fprintf(&c.buf, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"%s\"/>\n", x, y, w, h, col.hex())
+}
+
+func (c *svgCanvas) strokeRect(x, y, w, h, width float64, col color) {
+	fmt.Fprintf(&c.buf, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"none\" stroke=\"%s\" stroke-width=\"%.2f\"/>\n", x, y, w, h, col.hex(), width)
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2, width float64, col color) {
+	fmt.Fprintf(&c.buf, "<line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" stroke=\"%s\" stroke-width=\"%.2f\"/>\n", x1, y1, x2, y2, col.hex(), width)
+}
+
+func (c *svgCanvas) circle(cx, cy, r float64, col color) {
+	fmt.Fprintf(&c.buf, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"%.2f\" fill=\"%s\"/>\n", cx, cy, r, col.hex())
+}
+
+func (c *svgCanvas) text(x, y, size float64, s string, col color) {
+	fmt.Fprintf(&c.buf, "<text x=\"%.2f\" y=\"%.2f\" font-size=\"%.2f\" text-anchor=\"middle\" fill=\"%s\">%s</text>\n", x, y, size, col.hex(), html.EscapeString(s))
+}
+
+func (c color) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
@@ -0,0 +1,11 @@
+package storage
+
+import "errors"
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrUsernameTaken = errors.New("username already taken")
+	ErrSessionTaken  = errors.New("session already belongs to an account")
+	// ErrConflict means a write was based on a game that has since changed.
+	ErrConflict = errors.New("game was changed concurrently")
+)
@@ -2,10 +2,14 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"codenames/internal/model"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,50 +21,168 @@ func NewGameRepo(pool *pgxpool.Pool) *GameRepo {
 	return &GameRepo{pool: pool}
 }
 
-func (r *GameRepo) Create(ctx context.Context, roomID string, firstTeam model.Team) (model.Game, error) {
+// CreateWithCards inserts a new game in the playing phase together with its
+// board, in one transaction, and returns them with their generated IDs.
+func (r *GameRepo) CreateWithCards(ctx context.Context, roomID string, firstTeam model.Team, cards []model.Card, players []model.GamePlayer) (model.Game, []model.Card, error) {
+	roster, err := json.Marshal(players)
+	if err != nil {
+		return model.Game{}, nil, fmt.Errorf("encode players: %w", err)
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Game{}, nil, fmt.Errorf("create game: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var g model.Game
-	err := r.pool.QueryRow(ctx, `
-		INSERT INTO games (room_id, phase, current_team)
-		VALUES ($1, 'playing', $2)
-		RETURNING id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner
-	`, roomID, firstTeam).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner)
+	err = tx.QueryRow(ctx, `
+		INSERT INTO games (room_id, phase, current_team, first_team, players)
+		VALUES ($1, 'playing', $2, $2, $3)
+		RETURNING id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version
+	`, roomID, firstTeam, roster).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner, &g.Version)
 	if err != nil {
-		return model.Game{}, fmt.Errorf("create game: %w", err)
+		return model.Game{}, nil, fmt.Errorf("create game: %w", err)
 	}
-	return g, nil
+
+	created := make([]model.Card, 0, len(cards))
+	for _, c := range cards {
+		c.GameID = g.ID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO cards (game_id, word, card_type, position)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, c.GameID, c.Word, c.CardType, c.Position).Scan(&c.ID)
+		if err != nil {
+			return model.Game{}, nil, fmt.Errorf("create card: %w", err)
+		}
+		created = append(created, c)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Game{}, nil, fmt.Errorf("create game: %w", err)
+	}
+	return g, created, nil
 }
 
 func (r *GameRepo) GetActiveByRoomID(ctx context.Context, roomID string) (model.Game, error) {
 	var g model.Game
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner
+		SELECT id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version
 		FROM games WHERE room_id = $1 AND phase != 'lobby'
 		ORDER BY created_at DESC LIMIT 1
-	`, roomID).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner)
+	`, roomID).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner, &g.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Game{}, ErrNotFound
+	}
 	if err != nil {
 		return model.Game{}, fmt.Errorf("get active game: %w", err)
 	}
 	return g, nil
 }
 
-func (r *GameRepo) Update(ctx context.Context, g model.Game) error {
-	_, err := r.pool.Exec(ctx, `
-		UPDATE games SET phase=$2, current_team=$3, current_clue=$4, current_number=$5, guesses_left=$6, winner=$7
-		WHERE id=$1
-	`, g.ID, g.Phase, g.CurrentTeam, g.CurrentClue, g.CurrentNumber, g.GuessesLeft, g.Winner)
-	return err
+// Save writes the game, marks the revealed cards and appends the move in one
+// transaction. It only succeeds if the stored game is still at g.Version and
+// the cards are not revealed yet, and returns ErrConflict otherwise. On
+// success it returns the game's new version, which numbers the move.
+func (r *GameRepo) Save(ctx context.Context, g model.Game, move model.Move, revealed []model.Card) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("save game: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var version int
+	err = tx.QueryRow(ctx, `
+		UPDATE games SET phase=$2, current_team=$3, current_clue=$4, current_number=$5, guesses_left=$6, winner=$7, version=version+1
+		WHERE id=$1 AND version=$8
+		RETURNING version
+	`, g.ID, g.Phase, g.CurrentTeam, g.CurrentClue, g.CurrentNumber, g.GuessesLeft, g.Winner, g.Version).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrConflict
+	}
+	if err != nil {
+		return 0, fmt.Errorf("save game: %w", err)
+	}
+
+	for _, c := range revealed {
+		tag, err := tx.Exec(ctx, `
+			UPDATE cards SET revealed = true, revealed_by = $2 WHERE id = $1 AND NOT revealed
+		`, c.ID, c.RevealedBy)
+		if err != nil {
+			return 0, fmt.Errorf("reveal card: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, ErrConflict
+		}
+	}
+
+	move.GameID, move.Seq = g.ID, version
+	if err := insertMove(ctx, tx, move); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("save game: %w", err)
+	}
+	return version, nil
 }
 
-func (r *GameRepo) CreateCards(ctx context.Context, cards []model.Card) error {
+// Import inserts the game, its board and its history in one transaction.
+func (r *GameRepo) Import(ctx context.Context, roomID string, g model.Game, cards []model.Card, history model.GameHistory) (model.Game, error) {
+	roster, err := json.Marshal(history.Players)
+	if err != nil {
+		return model.Game{}, fmt.Errorf("encode players: %w", err)
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Game{}, fmt.Errorf("import game: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	g.RoomID = roomID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO games (room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version, first_team, players)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, roomID, g.Phase, g.CurrentTeam, g.CurrentClue, g.CurrentNumber, g.GuessesLeft, g.Winner, g.Version, history.FirstTeam, roster).Scan(&g.ID)
+	if err != nil {
+		return model.Game{}, fmt.Errorf("import game: %w", err)
+	}
 	for _, c := range cards {
-		_, err := r.pool.Exec(ctx, `
-			INSERT INTO cards (game_id, word, card_type, position)
-			VALUES ($1, $2, $3, $4)
-		`, c.GameID, c.Word, c.CardType, c.Position)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO cards (game_id, word, card_type, position, revealed, revealed_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, g.ID, c.Word, c.CardType, c.Position, c.Revealed, c.RevealedBy)
 		if err != nil {
-			return fmt.Errorf("create card: %w", err)
+			return model.Game{}, fmt.Errorf("import card: %w", err)
+		}
+	}
+	for i, m := range history.Moves {
+		m.GameID, m.Seq = g.ID, i+1
+		if err := insertMove(ctx, tx, m); err != nil {
+			return model.Game{}, err
 		}
 	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Game{}, fmt.Errorf("import game: %w", err)
+	}
+	return g, nil
+}
+
+// insertMove records the move, at m.CreatedAt if set and now otherwise.
+func insertMove(ctx context.Context, tx pgx.Tx, m model.Move) error {
+	var createdAt *time.Time
+	if !m.CreatedAt.IsZero() {
+		createdAt = &m.CreatedAt
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO game_moves (game_id, seq, kind, team, player_name, clue, number, position, winner, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10::timestamptz, now()))
+	`, m.GameID, m.Seq, m.Kind, m.Team, m.Player, m.Clue, m.Number, m.Position, m.Winner, createdAt)
+	if err != nil {
+		return fmt.Errorf("record move: %w", err)
+	}
 	return nil
 }
 
@@ -85,23 +207,61 @@ func (r *GameRepo) GetCardsByGameID(ctx context.Context, gameID string) ([]model
 	return cards, nil
 }
 
-func (r *GameRepo) RevealCard(ctx context.Context, cardID string, revealedBy model.Team) error {
-	_, err := r.pool.Exec(ctx, `
-		UPDATE cards SET revealed = true, revealed_by = $2 WHERE id = $1
-	`, cardID, revealedBy)
-	return err
+func (r *GameRepo) GetByID(ctx context.Context, id string) (model.Game, error) {
+	var g model.Game
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version
+		FROM games WHERE id = $1
+	`, id).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner, &g.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Game{}, ErrNotFound
+	}
+	if err != nil {
+		return model.Game{}, fmt.Errorf("get game: %w", err)
+	}
+	return g, nil
 }
 
-func (r *GameRepo) Deactivate(ctx context.Context, gameID string) error {
-	_, err := r.pool.Exec(ctx, `
-		UPDATE games SET phase = 'lobby' WHERE id = $1
+func (r *GameRepo) GetHistory(ctx context.Context, gameID string) (model.GameHistory, error) {
+	var h model.GameHistory
+	var roster []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT first_team, players, created_at FROM games WHERE id = $1
+	`, gameID).Scan(&h.FirstTeam, &roster, &h.StartedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.GameHistory{}, ErrNotFound
+	}
+	if err != nil {
+		return model.GameHistory{}, fmt.Errorf("get game history: %w", err)
+	}
+	if err := json.Unmarshal(roster, &h.Players); err != nil {
+		return model.GameHistory{}, fmt.Errorf("decode players: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT game_id, seq, kind, team, player_name, clue, number, position, winner, created_at
+		FROM game_moves WHERE game_id = $1 ORDER BY seq
 	`, gameID)
-	return err
+	if err != nil {
+		return model.GameHistory{}, fmt.Errorf("get moves: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m model.Move
+		if err := rows.Scan(&m.GameID, &m.Seq, &m.Kind, &m.Team, &m.Player, &m.Clue, &m.Number, &m.Position, &m.Winner, &m.CreatedAt); err != nil {
+			return model.GameHistory{}, fmt.Errorf("get moves: %w", err)
+		}
+		h.Moves = append(h.Moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return model.GameHistory{}, fmt.Errorf("get moves: %w", err)
+	}
+	return h, nil
 }
 
-func (r *GameRepo) SetFinished(ctx context.Context, gameID string, winner model.Team) error {
+func (r *GameRepo) Deactivate(ctx context.Context, gameID string) error {
 	_, err := r.pool.Exec(ctx, `
-		UPDATE games SET phase = 'finished', winner = $2 WHERE id = $1
-	`, gameID, winner)
+		UPDATE games SET phase = 'lobby', version = version + 1 WHERE id = $1
+	`, gameID)
 	return err
 }
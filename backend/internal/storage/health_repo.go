@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HealthRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewHealthRepo(pool *pgxpool.Pool) *HealthRepo {
+	return &HealthRepo{pool: pool}
+}
+
+func (r *HealthRepo) Ping(ctx context.Context) error {
+	return r.pool.Ping(ctx)
+}
+
+func (r *HealthRepo) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := r.pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type JanitorRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewJanitorRepo(pool *pgxpool.Pool) *JanitorRepo {
+	return &JanitorRepo{pool: pool}
+}
+
+func (r *JanitorRepo) DeleteIdleRooms(ctx context.Context, idleSince time.Time) (PurgeStats, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("delete idle rooms: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the rooms keeps players from joining them until they are gone.
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM rooms r
+		WHERE last_active_at < $1
+			AND NOT EXISTS (SELECT 1 FROM players p WHERE p.room_id = r.id AND p.is_online)
+		FOR UPDATE
+	`, idleSince)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("find idle rooms: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return PurgeStats{}, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return PurgeStats{}, fmt.Errorf("find idle rooms: %w", err)
+	}
+	if len(ids) == 0 {
+		return PurgeStats{}, nil
+	}
+
+	var stats PurgeStats
+	err = tx.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM players WHERE room_id = ANY($1)),
+			(SELECT COUNT(*) FROM games WHERE room_id = ANY($1)),
+			(SELECT COUNT(*) FROM cards c JOIN games g ON g.id = c.game_id WHERE g.room_id = ANY($1)),
+			(SELECT COUNT(*) FROM chat_messages WHERE room_id = ANY($1))
+	`, ids).Scan(&stats.Players, &stats.Games, &stats.Cards, &stats.ChatMessages)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("count idle room rows: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM rooms WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("delete idle rooms: %w", err)
+	}
+	stats.Rooms = tag.RowsAffected()
+
+	if err := tx.Commit(ctx); err != nil {
+		return PurgeStats{}, fmt.Errorf("delete idle rooms: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *JanitorRepo) ArchiveGames(ctx context.Context, startedBefore time.Time) (PurgeStats, error) {
+	var stats PurgeStats
+	err := r.pool.QueryRow(ctx, `
+		WITH deleted AS (
+			DELETE FROM cards c USING games g
+			WHERE g.id = c.game_id AND g.phase = 'lobby' AND g.created_at < $1
+			RETURNING c.game_id
+		)
+		SELECT COUNT(DISTINCT game_id), COUNT(*) FROM deleted
+	`, startedBefore).Scan(&stats.Games, &stats.Cards)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("archive games: %w", err)
+	}
+	return stats, nil
+}
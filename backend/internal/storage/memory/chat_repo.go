@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"math"
+
+	"codenames/internal/model"
+)
+
+type ChatRepo struct {
+	db *db
+}
+
+func (r *ChatRepo) Create(ctx context.Context, m model.ChatMessage) (model.ChatMessage, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	r.db.nextChatID++
+	m.ID = r.db.nextChatID
+	m.CreatedAt = now()
+	r.db.chat = append(r.db.chat, m)
+	return m, nil
+}
+
+func (r *ChatRepo) GetHistory(ctx context.Context, roomID string, channel model.ChatChannel, team model.Team, beforeID int64, limit int) ([]model.ChatMessage, error) {
+	if channel == model.ChatChannelRoom {
+		team = ""
+	}
+	if beforeID <= 0 {
+		beforeID = math.MaxInt64
+	}
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	// Messages are stored in ID order; walk back from the newest.
+	var page []model.ChatMessage
+	for i := len(r.db.chat) - 1; i >= 0 && len(page) < limit; i-- {
+		m := r.db.chat[i]
+		if m.RoomID == roomID && m.Channel == channel && m.Team == team && m.ID < beforeID {
+			page = append(page, m)
+		}
+	}
+	for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+		page[i], page[j] = page[j], page[i]
+	}
+	return page, nil
+}
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type gameRow struct {
+	game      model.Game
+	cards     []model.Card
+	seq       int64 // creation order
+	createdAt time.Time
+	firstTeam model.Team
+	players   []model.GamePlayer
+	moves     []model.Move
+}
+
+type GameRepo struct {
+	db *db
+}
+
+func (r *GameRepo) CreateWithCards(ctx context.Context, roomID string, firstTeam model.Team, cards []model.Card, players []model.GamePlayer) (model.Game, []model.Card, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if _, ok := r.db.rooms[roomID]; !ok {
+		return model.Game{}, nil, fmt.Errorf("create game: room %w", storage.ErrNotFound)
+	}
+	g := model.Game{ID: storage.NewUUID(), RoomID: roomID, Phase: model.PhasePlaying, CurrentTeam: firstTeam}
+	created := make([]model.Card, len(cards))
+	for i, c := range cards {
+		c.ID = storage.NewUUID()
+		c.GameID = g.ID
+		c.Revealed = false
+		c.RevealedBy = ""
+		created[i] = c
+	}
+	slices.SortFunc(created, func(a, b model.Card) int { return a.Position - b.Position })
+	r.db.gameSeq++
+	r.db.games[g.ID] = &gameRow{game: g, cards: created, seq: r.db.gameSeq, createdAt: now(), firstTeam: firstTeam, players: slices.Clone(players)}
+	return g, slices.Clone(created), nil
+}
+
+func (r *GameRepo) Import(ctx context.Context, roomID string, g model.Game, cards []model.Card, history model.GameHistory) (model.Game, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if _, ok := r.db.rooms[roomID]; !ok {
+		return model.Game{}, fmt.Errorf("import game: room %w", storage.ErrNotFound)
+	}
+	g.ID, g.RoomID = storage.NewUUID(), roomID
+	imported := make([]model.Card, len(cards))
+	for i, c := range cards {
+		c.ID = storage.NewUUID()
+		c.GameID = g.ID
+		imported[i] = c
+	}
+	slices.SortFunc(imported, func(a, b model.Card) int { return a.Position - b.Position })
+	createdAt := now()
+	moves := make([]model.Move, len(history.Moves))
+	for i, m := range history.Moves {
+		m.GameID, m.Seq = g.ID, i+1
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = createdAt
+		}
+		moves[i] = m
+	}
+	r.db.gameSeq++
+	r.db.games[g.ID] = &gameRow{
+		game: g, cards: imported, seq: r.db.gameSeq, createdAt: createdAt,
+		firstTeam: history.FirstTeam, players: slices.Clone(history.Players), moves: moves,
+	}
+	return g, nil
+}
+
+func (r *GameRepo) GetActiveByRoomID(ctx context.Context, roomID string) (model.Game, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	var latest *gameRow
+	for _, row := range r.db.games {
+		if row.game.RoomID != roomID || row.game.Phase == model.PhaseLobby {
+			continue
+		}
+		if latest == nil || row.seq > latest.seq {
+			latest = row
+		}
+	}
+	if latest == nil {
+		return model.Game{}, storage.ErrNotFound
+	}
+	return latest.game, nil
+}
+
+func (r *GameRepo) Save(ctx context.Context, g model.Game, move model.Move, revealed []model.Card) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.games[g.ID]
+	if !ok || row.game.Version != g.Version {
+		return 0, storage.ErrConflict
+	}
+	indexes := make([]int, len(revealed))
+	for i, c := range revealed {
+		idx := slices.IndexFunc(row.cards, func(rc model.Card) bool { return rc.ID == c.ID })
+		if idx < 0 || row.cards[idx].Revealed {
+			return 0, storage.ErrConflict
+		}
+		indexes[i] = idx
+	}
+
+	for i, idx := range indexes {
+		row.cards[idx].Revealed = true
+		row.cards[idx].RevealedBy = revealed[i].RevealedBy
+	}
+	g.RoomID = row.game.RoomID
+	g.Version++
+	row.game = g
+	move.GameID, move.Seq, move.CreatedAt = g.ID, g.Version, now()
+	row.moves = append(row.moves, move)
+	return g.Version, nil
+}
+
+func (r *GameRepo) GetCardsByGameID(ctx context.Context, gameID string) ([]model.Card, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.games[gameID]
+	if !ok {
+		return nil, nil
+	}
+	return slices.Clone(row.cards), nil
+}
+
+func (r *GameRepo) GetByID(ctx context.Context, id string) (model.Game, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.games[id]
+	if !ok {
+		return model.Game{}, storage.ErrNotFound
+	}
+	return row.game, nil
+}
+
+func (r *GameRepo) GetHistory(ctx context.Context, gameID string) (model.GameHistory, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	row, ok := r.db.games[gameID]
+	if !ok {
+		return model.GameHistory{}, storage.ErrNotFound
+	}
+	return model.GameHistory{
+		FirstTeam: row.firstTeam,
+		Players:   slices.Clone(row.players),
+		Moves:     slices.Clone(row.moves),
+		StartedAt: row.createdAt,
+	}, nil
+}
+
+func (r *GameRepo) Deactivate(ctx context.Context, gameID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if row, ok := r.db.games[gameID]; ok {
+		row.game.Phase = model.PhaseLobby
+		row.game.Version++
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package memory
+
+import "context"
+
+// HealthRepo reports the store as always healthy.
+type HealthRepo struct{}
+
+func (HealthRepo) Ping(ctx context.Context) error { return nil }
+
+func (HealthRepo) SchemaVersion(ctx context.Context) (uint, bool, error) { return 0, false, nil }
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type JanitorRepo struct {
+	db *db
+}
+
+func (r *JanitorRepo) DeleteIdleRooms(ctx context.Context, idleSince time.Time) (storage.PurgeStats, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	idle := make(map[string]bool)
+	for id := range r.db.rooms {
+		if r.db.lastActive[id].Before(idleSince) {
+			idle[id] = true
+		}
+	}
+	for _, p := range r.db.players {
+		if p.IsOnline {
+			delete(idle, p.RoomID)
+		}
+	}
+	return r.db.deleteRooms(idle), nil
+}
+
+// deleteRooms deletes the rooms with everything in them but their rating
+// history. The caller must hold d.mu.
+func (d *db) deleteRooms(ids map[string]bool) storage.PurgeStats {
+	var stats storage.PurgeStats
+	for id := range ids {
+		delete(d.rooms, id)
+		delete(d.lastActive, id)
+		stats.Rooms++
+	}
+	for id, p := range d.players {
+		if ids[p.RoomID] {
+			delete(d.players, id)
+			stats.Players++
+		}
+	}
+	for id, row := range d.games {
+		if ids[row.game.RoomID] {
+			delete(d.games, id)
+			stats.Games++
+			stats.Cards += int64(len(row.cards))
+		}
+	}
+	kept := d.chat[:0]
+	for _, m := range d.chat {
+		if ids[m.RoomID] {
+			stats.ChatMessages++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	d.chat = kept
+	return stats
+}
+
+func (r *JanitorRepo) ArchiveGames(ctx context.Context, startedBefore time.Time) (storage.PurgeStats, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var stats storage.PurgeStats
+	for _, row := range r.db.games {
+		if row.game.Phase == model.PhaseLobby && row.createdAt.Before(startedBefore) && len(row.cards) > 0 {
+			stats.Games++
+			stats.Cards += int64(len(row.cards))
+			row.cards = nil
+		}
+	}
+	return stats, nil
+}
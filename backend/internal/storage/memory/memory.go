@@ -0,0 +1,65 @@
+// Package memory implements storage.Store in process, for local development
+// and end-to-end tests without Postgres. Everything is lost on restart and
+// it cannot be shared between server instances.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+// db holds all the data of a store behind one lock, so every repo method is
+// atomic like a transaction.
+type db struct {
+	mu sync.Mutex
+
+	rooms      map[string]model.Room
+	lastActive map[string]time.Time // by room ID
+	players    map[string]model.Player
+	games      map[string]*gameRow
+	gameSeq    int64
+
+	chat       []model.ChatMessage
+	nextChatID int64
+
+	ratings map[ratingKey]model.Rating
+	history []historyRow
+
+	users map[string]model.User
+
+	wordPacks map[string]model.WordPack
+}
+
+// NewStore returns an empty store.
+func NewStore() storage.Store {
+	d := &db{
+		rooms:      make(map[string]model.Room),
+		lastActive: make(map[string]time.Time),
+		players:    make(map[string]model.Player),
+		games:      make(map[string]*gameRow),
+		ratings:    make(map[ratingKey]model.Rating),
+		users:      make(map[string]model.User),
+		wordPacks:  make(map[string]model.WordPack),
+	}
+	return storage.Store{
+		Rooms:    &RoomRepo{db: d},
+		Players:  &PlayerRepo{db: d},
+		Games:    &GameRepo{db: d},
+		Chat:     &ChatRepo{db: d},
+		Ratings:  &RatingRepo{db: d},
+		Users:    &UserRepo{db: d},
+		Janitor:  &JanitorRepo{db: d},
+		Notifier: NewNotifier(),
+		Health:   HealthRepo{},
+
+		WordPacks: &WordPackRepo{db: d},
+		Stats:     &StatsRepo{db: d},
+	}
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"testing"
+
+	"codenames/internal/storage"
+	"codenames/internal/storage/storagetest"
+)
+
+func TestStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store { return NewStore() })
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+const listenerQueueSize = 256
+
+// Notifier passes messages between listeners in this process.
+type Notifier struct {
+	mu        sync.Mutex
+	listeners map[string]map[chan string]bool
+}
+
+func NewNotifier() *Notifier {
+	return &Notifier{listeners: make(map[string]map[chan string]bool)}
+}
+
+func (n *Notifier) Notify(ctx context.Context, channel, payload string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.listeners[channel] {
+		select {
+		case ch <- payload:
+		default:
+			slog.Warn("listener queue full, dropping message", "channel", channel)
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) Listen(ctx context.Context, channel string, handle func(payload string)) {
+	ch := make(chan string, listenerQueueSize)
+	n.mu.Lock()
+	if n.listeners[channel] == nil {
+		n.listeners[channel] = make(map[chan string]bool)
+	}
+	n.listeners[channel][ch] = true
+	n.mu.Unlock()
+
+	defer func() {
+		n.mu.Lock()
+		delete(n.listeners[channel], ch)
+		n.mu.Unlock()
+	}()
+	for {
+		select {
+		case payload := <-ch:
+			handle(payload)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type PlayerRepo struct {
+	db *db
+}
+
+func (r *PlayerRepo) Upsert(ctx context.Context, roomID, sessionID, name string) (model.Player, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if _, ok := r.db.rooms[roomID]; !ok {
+		return model.Player{}, fmt.Errorf("upsert player: room %w", storage.ErrNotFound)
+	}
+	p, ok := r.db.playerBySession(sessionID, roomID)
+	if !ok {
+		p = model.Player{ID: storage.NewUUID(), RoomID: roomID, SessionID: sessionID}
+	}
+	p.Name = name
+	p.IsOnline = true
+	p.Presence = model.PresenceOnline
+	r.db.players[p.ID] = p
+	r.db.lastActive[roomID] = now()
+	return p, nil
+}
+
+func (r *PlayerRepo) GetByRoomID(ctx context.Context, roomID string) ([]model.Player, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	var players []model.Player
+	for _, p := range r.db.players {
+		if p.RoomID == roomID {
+			players = append(players, p)
+		}
+	}
+	slices.SortFunc(players, func(a, b model.Player) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return players, nil
+}
+
+func (r *PlayerRepo) GetBySessionAndRoom(ctx context.Context, sessionID, roomID string) (model.Player, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	p, ok := r.db.playerBySession(sessionID, roomID)
+	if !ok {
+		return model.Player{}, fmt.Errorf("get player by session: %w", storage.ErrNotFound)
+	}
+	return p, nil
+}
+
+func (r *PlayerRepo) SetTeamRole(ctx context.Context, playerID string, team model.Team, role model.Role) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if p, ok := r.db.players[playerID]; ok {
+		p.Team, p.Role = team, role
+		r.db.players[playerID] = p
+	}
+	return nil
+}
+
+// SetPresence also marks the player's room as active, see storage.JanitorStore.
+func (r *PlayerRepo) SetPresence(ctx context.Context, playerID string, presence model.Presence) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if p, ok := r.db.players[playerID]; ok {
+		p.Presence = presence
+		p.IsOnline = presence != model.PresenceOffline
+		r.db.players[playerID] = p
+		r.db.lastActive[p.RoomID] = now()
+	}
+	return nil
+}
+
+func (r *PlayerRepo) ResetTeamsAndRoles(ctx context.Context, roomID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for id, p := range r.db.players {
+		if p.RoomID == roomID {
+			p.Team, p.Role = "", ""
+			r.db.players[id] = p
+		}
+	}
+	return nil
+}
+
+func (r *PlayerRepo) Delete(ctx context.Context, playerID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if _, ok := r.db.players[playerID]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(r.db.players, playerID)
+	for i, m := range r.db.chat {
+		if m.PlayerID == playerID {
+			r.db.chat[i].PlayerID = ""
+		}
+	}
+	return nil
+}
+
+// playerBySession finds a player. The caller must hold d.mu.
+func (d *db) playerBySession(sessionID, roomID string) (model.Player, bool) {
+	for _, p := range d.players {
+		if p.SessionID == sessionID && p.RoomID == roomID {
+			return p, true
+		}
+	}
+	return model.Player{}, false
+}
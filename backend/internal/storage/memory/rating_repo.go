@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"codenames/internal/model"
+)
+
+type ratingKey struct {
+	sessionID string
+	role      model.Role
+}
+
+type historyRow struct {
+	sessionID string
+	role      model.Role
+	gameID    string
+	roomID    string
+	won       bool
+}
+
+type RatingRepo struct {
+	db *db
+}
+
+func (r *RatingRepo) GetBySessions(ctx context.Context, sessionIDs []string) ([]model.Rating, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	ratings := []model.Rating{}
+	for key, rt := range r.db.ratings {
+		if slices.Contains(sessionIDs, key.sessionID) {
+			ratings = append(ratings, rt)
+		}
+	}
+	return ratings, nil
+}
+
+func (r *RatingRepo) ApplyGameResult(ctx context.Context, gameID, roomID string, changes []model.RatingChange) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, c := range changes {
+		if slices.ContainsFunc(r.db.history, func(h historyRow) bool {
+			return h.gameID == gameID && h.sessionID == c.SessionID && h.role == c.Role
+		}) {
+			continue
+		}
+		r.db.history = append(r.db.history, historyRow{sessionID: c.SessionID, role: c.Role, gameID: gameID, roomID: roomID, won: c.Won})
+
+		key := ratingKey{sessionID: c.SessionID, role: c.Role}
+		rt, ok := r.db.ratings[key]
+		if !ok {
+			rt = model.Rating{SessionID: c.SessionID, Role: c.Role}
+		}
+		rt.PlayerName = c.PlayerName
+		rt.Rating = c.After
+		rt.Games++
+		if c.Won {
+			rt.Wins++
+		}
+		rt.UpdatedAt = now()
+		r.db.ratings[key] = rt
+	}
+	return nil
+}
+
+func (r *RatingRepo) Leaderboard(ctx context.Context, role model.Role, limit int) ([]model.Rating, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	ratings := []model.Rating{}
+	for key, rt := range r.db.ratings {
+		if key.role == role {
+			ratings = append(ratings, rt)
+		}
+	}
+	return topRatings(ratings, limit), nil
+}
+
+func (r *RatingRepo) RoomLeaderboard(ctx context.Context, roomID string, role model.Role, limit int) ([]model.Rating, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	inRoom := make(map[ratingKey]model.Rating)
+	for _, h := range r.db.history {
+		key := ratingKey{sessionID: h.sessionID, role: h.role}
+		rt, ok := r.db.ratings[key]
+		if h.roomID != roomID || h.role != role || !ok {
+			continue
+		}
+		if counted, ok := inRoom[key]; ok {
+			rt = counted
+		} else {
+			rt.Games, rt.Wins = 0, 0
+		}
+		rt.Games++
+		if h.won {
+			rt.Wins++
+		}
+		inRoom[key] = rt
+	}
+	ratings := []model.Rating{}
+	for _, rt := range inRoom {
+		ratings = append(ratings, rt)
+	}
+	return topRatings(ratings, limit), nil
+}
+
+// topRatings sorts the ratings highest first and keeps up to limit of them.
+func topRatings(ratings []model.Rating, limit int) []model.Rating {
+	slices.SortFunc(ratings, func(a, b model.Rating) int {
+		return cmp.Or(cmp.Compare(b.Rating, a.Rating), cmp.Compare(a.SessionID, b.SessionID))
+	})
+	if len(ratings) > limit {
+		ratings = ratings[:limit]
+	}
+	return ratings
+}
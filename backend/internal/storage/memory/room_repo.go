@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type RoomRepo struct {
+	db *db
+}
+
+func (r *RoomRepo) Create(ctx context.Context) (model.Room, error) {
+	id, err := storage.NewRoomID()
+	if err != nil {
+		return model.Room{}, err
+	}
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if _, ok := r.db.rooms[id]; ok {
+		return model.Room{}, fmt.Errorf("create room: id %s already exists", id)
+	}
+	room := model.Room{ID: id, Settings: model.DefaultRoomSettings(), CreatedAt: now()}
+	r.db.rooms[id] = room
+	r.db.lastActive[id] = room.CreatedAt
+	return cloneRoom(room), nil
+}
+
+func (r *RoomRepo) GetByID(ctx context.Context, id string) (model.Room, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	room, ok := r.db.rooms[id]
+	if !ok {
+		return model.Room{}, fmt.Errorf("get room: %w", storage.ErrNotFound)
+	}
+	return cloneRoom(room), nil
+}
+
+func (r *RoomRepo) UpdateSettings(ctx context.Context, id string, settings model.RoomSettings) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	room, ok := r.db.rooms[id]
+	if !ok {
+		return nil
+	}
+	room.Settings = settings
+	r.db.rooms[id] = cloneRoom(room)
+	return nil
+}
+
+func (r *RoomRepo) List(ctx context.Context) ([]model.RoomSummary, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	summaries := make(map[string]*model.RoomSummary, len(r.db.rooms))
+	for id, room := range r.db.rooms {
+		summaries[id] = &model.RoomSummary{ID: id, CreatedAt: room.CreatedAt, LastActiveAt: r.db.lastActive[id], Phase: model.PhaseLobby}
+	}
+	for _, p := range r.db.players {
+		if s, ok := summaries[p.RoomID]; ok {
+			s.Players++
+			if p.IsOnline {
+				s.OnlinePlayers++
+			}
+		}
+	}
+	latest := make(map[string]int64)
+	for _, row := range r.db.games {
+		s, ok := summaries[row.game.RoomID]
+		if !ok || row.game.Phase == model.PhaseLobby || row.seq < latest[s.ID] {
+			continue
+		}
+		latest[s.ID] = row.seq
+		s.Phase = row.game.Phase
+	}
+
+	rooms := make([]model.RoomSummary, 0, len(summaries))
+	for _, s := range summaries {
+		rooms = append(rooms, *s)
+	}
+	slices.SortFunc(rooms, func(a, b model.RoomSummary) int {
+		return cmp.Or(b.LastActiveAt.Compare(a.LastActiveAt), cmp.Compare(a.ID, b.ID))
+	})
+	return rooms, nil
+}
+
+func (r *RoomRepo) Delete(ctx context.Context, id string) (storage.PurgeStats, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if _, ok := r.db.rooms[id]; !ok {
+		return storage.PurgeStats{}, storage.ErrNotFound
+	}
+	return r.db.deleteRooms(map[string]bool{id: true}), nil
+}
+
+// cloneRoom copies the slices of the room's settings, so callers can't
+// change stored rooms through them.
+func cloneRoom(room model.Room) model.Room {
+	room.Settings.WordPacks = slices.Clone(room.Settings.WordPacks)
+	return room
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type StatsRepo struct {
+	db *db
+}
+
+func (r *StatsRepo) Usage(ctx context.Context, since time.Time) (storage.UsageStats, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	s := storage.UsageStats{
+		Rooms:        int64(len(r.db.rooms)),
+		Players:      int64(len(r.db.players)),
+		Users:        int64(len(r.db.users)),
+		Games:        int64(len(r.db.games)),
+		ChatMessages: int64(len(r.db.chat)),
+		WordPacks:    int64(len(r.db.wordPacks)),
+	}
+	for _, room := range r.db.rooms {
+		if !room.CreatedAt.Before(since) {
+			s.RoomsSince++
+		}
+	}
+	active := make(map[string]bool)
+	for _, p := range r.db.players {
+		if p.IsOnline {
+			s.OnlinePlayers++
+			active[p.RoomID] = true
+		}
+	}
+	s.ActiveRooms = int64(len(active))
+	for _, row := range r.db.games {
+		if row.game.Phase == model.PhasePlaying {
+			s.GamesPlaying++
+		}
+		if !row.createdAt.Before(since) {
+			s.GamesSince++
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type UserRepo struct {
+	db *db
+}
+
+func (r *UserRepo) Create(ctx context.Context, username, passwordHash, sessionID string) (model.User, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, u := range r.db.users {
+		if u.Username == username {
+			return model.User{}, storage.ErrUsernameTaken
+		}
+		if u.SessionID == sessionID {
+			return model.User{}, storage.ErrSessionTaken
+		}
+	}
+	u := model.User{ID: storage.NewUUID(), Username: username, PasswordHash: passwordHash, SessionID: sessionID, CreatedAt: now()}
+	r.db.users[u.ID] = u
+	return u, nil
+}
+
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, u := range r.db.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return model.User{}, storage.ErrNotFound
+}
@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"codenames/internal/model"
+)
+
+type WordPackRepo struct {
+	db *db
+}
+
+func (r *WordPackRepo) Save(ctx context.Context, name string, pack model.WordPack) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	pack.Words = slices.Clone(pack.Words)
+	r.db.wordPacks[name] = pack
+	return nil
+}
+
+func (r *WordPackRepo) List(ctx context.Context) (map[string]model.WordPack, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return maps.Clone(r.db.wordPacks), nil
+}
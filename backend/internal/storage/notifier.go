@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresNotifier passes messages between instances with Postgres
+// LISTEN/NOTIFY.
+type PostgresNotifier struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresNotifier(pool *pgxpool.Pool) *PostgresNotifier {
+	return &PostgresNotifier{pool: pool}
+}
+
+func (n *PostgresNotifier) Notify(ctx context.Context, channel, payload string) error {
+	_, err := n.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, channel, payload)
+	if err != nil {
+		return fmt.Errorf("notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Listen holds one pool connection for the notifications and reconnects
+// after errors.
+func (n *PostgresNotifier) Listen(ctx context.Context, channel string, handle func(payload string)) {
+	for {
+		err := n.listen(ctx, channel, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("listen failed, reconnecting", "channel", channel, "err", err)
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *PostgresNotifier) listen(ctx context.Context, channel string, handle func(payload string)) error {
+	conn, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection is still subscribed, so don't give it back to the pool.
+	defer conn.Hijack().Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		note, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(note.Payload)
+	}
+}
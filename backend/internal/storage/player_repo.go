@@ -2,10 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"codenames/internal/model"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,11 +22,16 @@ func NewPlayerRepo(pool *pgxpool.Pool) *PlayerRepo {
 func (r *PlayerRepo) Upsert(ctx context.Context, roomID, sessionID, name string) (model.Player, error) {
 	var p model.Player
 	err := r.pool.QueryRow(ctx, `
-		INSERT INTO players (room_id, session_id, name, is_online)
-		VALUES ($1, $2, $3, true)
-		ON CONFLICT (room_id, session_id) DO UPDATE SET name = EXCLUDED.name, is_online = true
-		RETURNING id, room_id, session_id, name, team, role, is_online
-	`, roomID, sessionID, name).Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline)
+		WITH p AS (
+			INSERT INTO players (room_id, session_id, name, is_online, presence)
+			VALUES ($1, $2, $3, true, 'online')
+			ON CONFLICT (room_id, session_id) DO UPDATE SET name = EXCLUDED.name, is_online = true, presence = 'online'
+			RETURNING id, room_id, session_id, name, team, role, is_online, presence
+		), touch AS (
+			UPDATE rooms SET last_active_at = now() WHERE id = $1
+		)
+		SELECT * FROM p
+	`, roomID, sessionID, name).Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline, &p.Presence)
 	if err != nil {
 		return model.Player{}, fmt.Errorf("upsert player: %w", err)
 	}
@@ -33,7 +40,7 @@ func (r *PlayerRepo) Upsert(ctx context.Context, roomID, sessionID, name string)
 
 func (r *PlayerRepo) GetByRoomID(ctx context.Context, roomID string) ([]model.Player, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, room_id, session_id, name, team, role, is_online
+		SELECT id, room_id, session_id, name, team, role, is_online, presence
 		FROM players WHERE room_id = $1
 		ORDER BY name
 	`, roomID)
@@ -45,7 +52,7 @@ func (r *PlayerRepo) GetByRoomID(ctx context.Context, roomID string) ([]model.Pl
 	var players []model.Player
 	for rows.Next() {
 		var p model.Player
-		if err := rows.Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline); err != nil {
+		if err := rows.Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline, &p.Presence); err != nil {
 			return nil, err
 		}
 		players = append(players, p)
@@ -56,9 +63,12 @@ func (r *PlayerRepo) GetByRoomID(ctx context.Context, roomID string) ([]model.Pl
 func (r *PlayerRepo) GetBySessionAndRoom(ctx context.Context, sessionID, roomID string) (model.Player, error) {
 	var p model.Player
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, room_id, session_id, name, team, role, is_online
+		SELECT id, room_id, session_id, name, team, role, is_online, presence
 		FROM players WHERE session_id = $1 AND room_id = $2
-	`, sessionID, roomID).Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline)
+	`, sessionID, roomID).Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline, &p.Presence)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Player{}, ErrNotFound
+	}
 	if err != nil {
 		return model.Player{}, fmt.Errorf("get player by session: %w", err)
 	}
@@ -72,10 +82,14 @@ func (r *PlayerRepo) SetTeamRole(ctx context.Context, playerID string, team mode
 	return err
 }
 
-func (r *PlayerRepo) SetOnline(ctx context.Context, playerID string, online bool) error {
+// SetPresence also marks the player's room as active, see JanitorStore.
+func (r *PlayerRepo) SetPresence(ctx context.Context, playerID string, presence model.Presence) error {
 	_, err := r.pool.Exec(ctx, `
-		UPDATE players SET is_online = $2 WHERE id = $1
-	`, playerID, online)
+		WITH p AS (
+			UPDATE players SET presence = $2, is_online = $2 != 'offline' WHERE id = $1 RETURNING room_id
+		)
+		UPDATE rooms SET last_active_at = now() WHERE id IN (SELECT room_id FROM p)
+	`, playerID, presence)
 	return err
 }
 
@@ -85,3 +99,14 @@ func (r *PlayerRepo) ResetTeamsAndRoles(ctx context.Context, roomID string) erro
 	`, roomID)
 	return err
 }
+
+func (r *PlayerRepo) Delete(ctx context.Context, playerID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM players WHERE id = $1`, playerID)
+	if err != nil {
+		return fmt.Errorf("delete player: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
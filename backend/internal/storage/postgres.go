@@ -3,7 +3,10 @@ package storage
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -11,10 +14,16 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// NewPool connects to the database. Every query run on the pool is traced.
 func NewPool(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(ctx, databaseURL)
+	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("pgxpool.New: %w", err)
+		return nil, fmt.Errorf("pgxpool.ParseConfig: %w", err)
+	}
+	config.ConnConfig.Tracer = queryTracer{}
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("pgxpool.NewWithConfig: %w", err)
 	}
 	if err := pool.Ping(ctx); err != nil {
 		return nil, fmt.Errorf("pool.Ping: %w", err)
@@ -22,14 +31,47 @@ func NewPool(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
-func RunMigrations(databaseURL string, migrationsPath string) error {
+// NewMigrate returns a migrate instance applying the migrations in
+// migrationsPath to the database.
+func NewMigrate(databaseURL string, migrationsPath string) (*migrate.Migrate, error) {
 	m, err := migrate.New("file://"+migrationsPath, databaseURL)
 	if err != nil {
-		return fmt.Errorf("migrate.New: %w", err)
+		return nil, fmt.Errorf("migrate.New: %w", err)
 	}
+	return m, nil
+}
+
+func RunMigrations(databaseURL string, migrationsPath string) error {
+	m, err := NewMigrate(databaseURL, migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 		return fmt.Errorf("migrate.Up: %w", err)
 	}
-	log.Println("migrations applied successfully")
+	slog.Info("migrations applied")
 	return nil
 }
+
+// LatestMigration returns the highest version of the migrations in path,
+// the schema version the server expects once they are applied.
+func LatestMigration(path string) (uint, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+	var latest uint64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+		prefix, _, _ := strings.Cut(e.Name(), "_")
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s: invalid version", e.Name())
+		}
+		latest = max(latest, v)
+	}
+	return uint(latest), nil
+}
@@ -0,0 +1,36 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"codenames/internal/storage"
+	"codenames/internal/storage/storagetest"
+)
+
+// TestStore runs the backend tests against the Postgres database at
+// TEST_DATABASE_URL, which they empty. Without it the test is skipped.
+func TestStore(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	if err := storage.RunMigrations(url, "../../migrations"); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	pool, err := storage.NewPool(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		if _, err := pool.Exec(context.Background(), `
+			TRUNCATE rooms, games, players, cards, chat_messages, ratings, rating_history, users, game_moves CASCADE
+		`); err != nil {
+			t.Fatalf("empty database: %v", err)
+		}
+		return storage.NewPostgresStore(pool)
+	})
+}
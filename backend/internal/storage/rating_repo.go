@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"codenames/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRatingRepo(pool *pgxpool.Pool) *RatingRepo {
+	return &RatingRepo{pool: pool}
+}
+
+func (r *RatingRepo) GetBySessions(ctx context.Context, sessionIDs []string) ([]model.Rating, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT session_id, player_name, role, rating, games, wins, updated_at
+		FROM ratings WHERE session_id = ANY($1)
+	`, sessionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get ratings: %w", err)
+	}
+	defer rows.Close()
+	return scanRatings(rows)
+}
+
+// ApplyGameResult stores the rating changes of a finished game. Changes already
+// recorded for the game are skipped, so applying a result twice is harmless.
+func (r *RatingRepo) ApplyGameResult(ctx context.Context, gameID, roomID string, changes []model.RatingChange) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("apply game result: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, c := range changes {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO rating_history (session_id, role, game_id, room_id, rating_before, rating_after, won)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (game_id, session_id, role) DO NOTHING
+		`, c.SessionID, c.Role, gameID, roomID, c.Before, c.After, c.Won)
+		if err != nil {
+			return fmt.Errorf("insert rating history: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			continue
+		}
+
+		wins := 0
+		if c.Won {
+			wins = 1
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO ratings (session_id, role, player_name, rating, games, wins)
+			VALUES ($1, $2, $3, $4, 1, $5)
+			ON CONFLICT (session_id, role) DO UPDATE SET
+				player_name = EXCLUDED.player_name,
+				rating = EXCLUDED.rating,
+				games = ratings.games + 1,
+				wins = ratings.wins + EXCLUDED.wins,
+				updated_at = now()
+		`, c.SessionID, c.Role, c.PlayerName, c.After, wins)
+		if err != nil {
+			return fmt.Errorf("upsert rating: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Leaderboard returns the highest rated players in a role across all rooms.
+func (r *RatingRepo) Leaderboard(ctx context.Context, role model.Role, limit int) ([]model.Rating, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT session_id, player_name, role, rating, games, wins, updated_at
+		FROM ratings WHERE role = $1
+		ORDER BY rating DESC LIMIT $2
+	`, role, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get leaderboard: %w", err)
+	}
+	defer rows.Close()
+	return scanRatings(rows)
+}
+
+// RoomLeaderboard returns the players who finished a game in the room, with
+// their current rating but games and wins counted in this room only.
+func (r *RatingRepo) RoomLeaderboard(ctx context.Context, roomID string, role model.Role, limit int) ([]model.Rating, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT r.session_id, r.player_name, r.role, r.rating,
+			COUNT(h.id)::int, (COUNT(h.id) FILTER (WHERE h.won))::int, r.updated_at
+		FROM ratings r
+		JOIN rating_history h ON h.session_id = r.session_id AND h.role = r.role
+		WHERE h.room_id = $1 AND r.role = $2
+		GROUP BY r.session_id, r.role
+		ORDER BY r.rating DESC LIMIT $3
+	`, roomID, role, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get room leaderboard: %w", err)
+	}
+	defer rows.Close()
+	return scanRatings(rows)
+}
+
+func scanRatings(rows pgx.Rows) ([]model.Rating, error) {
+	ratings := []model.Rating{}
+	for rows.Next() {
+		var rt model.Rating
+		if err := rows.Scan(&rt.SessionID, &rt.PlayerName, &rt.Role, &rt.Rating, &rt.Games, &rt.Wins, &rt.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, rt)
+	}
+	return ratings, rows.Err()
+}
@@ -2,12 +2,13 @@ package storage
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"codenames/internal/model"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,35 +21,127 @@ func NewRoomRepo(pool *pgxpool.Pool) *RoomRepo {
 }
 
 func (r *RoomRepo) Create(ctx context.Context) (model.Room, error) {
-	id, err := generateRoomID()
+	id, err := NewRoomID()
 	if err != nil {
 		return model.Room{}, err
 	}
+	settings, err := json.Marshal(model.DefaultRoomSettings())
+	if err != nil {
+		return model.Room{}, fmt.Errorf("encode settings: %w", err)
+	}
 	var room model.Room
+	var raw []byte
 	err = r.pool.QueryRow(ctx,
-		`INSERT INTO rooms (id) VALUES ($1) RETURNING id, created_at`, id,
-	).Scan(&room.ID, &room.CreatedAt)
+		`INSERT INTO rooms (id, settings) VALUES ($1, $2) RETURNING id, settings, created_at`, id, settings,
+	).Scan(&room.ID, &raw, &room.CreatedAt)
 	if err != nil {
 		return model.Room{}, fmt.Errorf("create room: %w", err)
 	}
+	if room.Settings, err = DecodeSettings(raw); err != nil {
+		return model.Room{}, err
+	}
 	return room, nil
 }
 
 func (r *RoomRepo) GetByID(ctx context.Context, id string) (model.Room, error) {
 	var room model.Room
+	var raw []byte
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, created_at FROM rooms WHERE id = $1`, id,
-	).Scan(&room.ID, &room.CreatedAt)
+		`SELECT id, settings, created_at FROM rooms WHERE id = $1`, id,
+	).Scan(&room.ID, &raw, &room.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Room{}, fmt.Errorf("get room: %w", ErrNotFound)
+	}
 	if err != nil {
 		return model.Room{}, fmt.Errorf("get room: %w", err)
 	}
+	if room.Settings, err = DecodeSettings(raw); err != nil {
+		return model.Room{}, err
+	}
 	return room, nil
 }
 
-func generateRoomID() (string, error) {
-	b := make([]byte, 4)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
+func (r *RoomRepo) UpdateSettings(ctx context.Context, id string, settings model.RoomSettings) error {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("encode settings: %w", err)
+	}
+	_, err = r.pool.Exec(ctx, `
+		UPDATE rooms SET settings = $2 WHERE id = $1
+	`, id, raw)
+	return err
+}
+
+func (r *RoomRepo) List(ctx context.Context) ([]model.RoomSummary, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT r.id, r.created_at, r.last_active_at,
+			(SELECT COUNT(*) FROM players p WHERE p.room_id = r.id),
+			(SELECT COUNT(*) FROM players p WHERE p.room_id = r.id AND p.is_online),
+			COALESCE((
+				SELECT g.phase FROM games g WHERE g.room_id = r.id AND g.phase != 'lobby'
+				ORDER BY g.created_at DESC LIMIT 1
+			), 'lobby')
+		FROM rooms r
+		ORDER BY r.last_active_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list rooms: %w", err)
+	}
+	defer rows.Close()
+
+	var rooms []model.RoomSummary
+	for rows.Next() {
+		var s model.RoomSummary
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.LastActiveAt, &s.Players, &s.OnlinePlayers, &s.Phase); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list rooms: %w", err)
+	}
+	return rooms, nil
+}
+
+func (r *RoomRepo) Delete(ctx context.Context, id string) (PurgeStats, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("delete room: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var stats PurgeStats
+	err = tx.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM players WHERE room_id = $1),
+			(SELECT COUNT(*) FROM games WHERE room_id = $1),
+			(SELECT COUNT(*) FROM cards c JOIN games g ON g.id = c.game_id WHERE g.room_id = $1),
+			(SELECT COUNT(*) FROM chat_messages WHERE room_id = $1)
+	`, id).Scan(&stats.Players, &stats.Games, &stats.Cards, &stats.ChatMessages)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("count room rows: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM rooms WHERE id = $1`, id)
+	if err != nil {
+		return PurgeStats{}, fmt.Errorf("delete room: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return PurgeStats{}, ErrNotFound
+	}
+	stats.Rooms = 1
+
+	if err := tx.Commit(ctx); err != nil {
+		return PurgeStats{}, fmt.Errorf("delete room: %w", err)
+	}
+	return stats, nil
+}
+
+// DecodeSettings fills in defaults for any field missing from the stored
+// document, so rooms created before a setting existed keep working.
+func DecodeSettings(raw []byte) (model.RoomSettings, error) {
+	s := model.DefaultRoomSettings()
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return model.RoomSettings{}, fmt.Errorf("decode settings: %w", err)
 	}
-	return hex.EncodeToString(b), nil
+	return s, nil
 }
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"codenames/internal/model"
+)
+
+type ChatRepo struct {
+	db *sql.DB
+}
+
+func NewChatRepo(db *sql.DB) *ChatRepo {
+	return &ChatRepo{db: db}
+}
+
+func (r *ChatRepo) Create(ctx context.Context, m model.ChatMessage) (model.ChatMessage, error) {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO chat_messages (room_id, player_id, player_name, channel, team, text)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id, created_at
+	`, m.RoomID, m.PlayerID, m.PlayerName, m.Channel, m.Team, m.Text).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return model.ChatMessage{}, fmt.Errorf("create chat message: %w", err)
+	}
+	return m, nil
+}
+
+// GetHistory returns up to limit messages of a channel older than beforeID,
+// oldest first. A zero beforeID starts from the newest message.
+// Team is ignored for the room channel.
+func (r *ChatRepo) GetHistory(ctx context.Context, roomID string, channel model.ChatChannel, team model.Team, beforeID int64, limit int) ([]model.ChatMessage, error) {
+	if channel == model.ChatChannelRoom {
+		team = ""
+	}
+	if beforeID <= 0 {
+		beforeID = math.MaxInt64
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, room_id, COALESCE(player_id, ''), player_name, channel, team, text, created_at
+		FROM (
+			SELECT * FROM chat_messages
+			WHERE room_id = ? AND channel = ? AND team = ? AND id < ?
+			ORDER BY id DESC LIMIT ?
+		) page
+		ORDER BY id
+	`, roomID, channel, team, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get chat history: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []model.ChatMessage
+	for rows.Next() {
+		var m model.ChatMessage
+		if err := rows.Scan(&m.ID, &m.RoomID, &m.PlayerID, &m.PlayerName, &m.Channel, &m.Team, &m.Text, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
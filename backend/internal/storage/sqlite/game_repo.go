@@ -0,0 +1,258 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type GameRepo struct {
+	db *sql.DB
+}
+
+func NewGameRepo(db *sql.DB) *GameRepo {
+	return &GameRepo{db: db}
+}
+
+func (r *GameRepo) CreateWithCards(ctx context.Context, roomID string, firstTeam model.Team, cards []model.Card, players []model.GamePlayer) (model.Game, []model.Card, error) {
+	roster, err := json.Marshal(players)
+	if err != nil {
+		return model.Game{}, nil, fmt.Errorf("encode players: %w", err)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Game{}, nil, fmt.Errorf("create game: %w", err)
+	}
+	defer tx.Rollback()
+
+	var g model.Game
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO games (id, room_id, phase, current_team, first_team, players)
+		VALUES (?1, ?2, 'playing', ?3, ?3, ?4)
+		RETURNING id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version
+	`, storage.NewUUID(), roomID, firstTeam, string(roster)).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner, &g.Version)
+	if err != nil {
+		return model.Game{}, nil, fmt.Errorf("create game: %w", err)
+	}
+
+	created := make([]model.Card, 0, len(cards))
+	for _, c := range cards {
+		c.ID = storage.NewUUID()
+		c.GameID = g.ID
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO cards (id, game_id, word, card_type, position)
+			VALUES (?, ?, ?, ?, ?)
+		`, c.ID, c.GameID, c.Word, c.CardType, c.Position)
+		if err != nil {
+			return model.Game{}, nil, fmt.Errorf("create card: %w", err)
+		}
+		created = append(created, c)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Game{}, nil, fmt.Errorf("create game: %w", err)
+	}
+	return g, created, nil
+}
+
+func (r *GameRepo) GetActiveByRoomID(ctx context.Context, roomID string) (model.Game, error) {
+	var g model.Game
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version
+		FROM games WHERE room_id = ? AND phase != 'lobby'
+		ORDER BY created_at DESC, rowid DESC LIMIT 1
+	`, roomID).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner, &g.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Game{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return model.Game{}, fmt.Errorf("get active game: %w", err)
+	}
+	return g, nil
+}
+
+func (r *GameRepo) Save(ctx context.Context, g model.Game, move model.Move, revealed []model.Card) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("save game: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE games SET phase=?, current_team=?, current_clue=?, current_number=?, guesses_left=?, winner=?, version=version+1
+		WHERE id=? AND version=?
+		RETURNING version
+	`, g.Phase, g.CurrentTeam, g.CurrentClue, g.CurrentNumber, g.GuessesLeft, g.Winner, g.ID, g.Version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrConflict
+	}
+	if err != nil {
+		return 0, fmt.Errorf("save game: %w", err)
+	}
+
+	for _, c := range revealed {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE cards SET revealed = 1, revealed_by = ? WHERE id = ? AND NOT revealed
+		`, c.RevealedBy, c.ID)
+		if err != nil {
+			return 0, fmt.Errorf("reveal card: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return 0, storage.ErrConflict
+		}
+	}
+
+	move.GameID, move.Seq = g.ID, version
+	if err := insertMove(ctx, tx, move); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("save game: %w", err)
+	}
+	return version, nil
+}
+
+func (r *GameRepo) Import(ctx context.Context, roomID string, g model.Game, cards []model.Card, history model.GameHistory) (model.Game, error) {
+	roster, err := json.Marshal(history.Players)
+	if err != nil {
+		return model.Game{}, fmt.Errorf("encode players: %w", err)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Game{}, fmt.Errorf("import game: %w", err)
+	}
+	defer tx.Rollback()
+
+	g.ID, g.RoomID = storage.NewUUID(), roomID
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO games (id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version, first_team, players)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, g.ID, roomID, g.Phase, g.CurrentTeam, g.CurrentClue, g.CurrentNumber, g.GuessesLeft, g.Winner, g.Version, history.FirstTeam, string(roster))
+	if err != nil {
+		return model.Game{}, fmt.Errorf("import game: %w", err)
+	}
+	for _, c := range cards {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO cards (id, game_id, word, card_type, position, revealed, revealed_by)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, storage.NewUUID(), g.ID, c.Word, c.CardType, c.Position, c.Revealed, c.RevealedBy)
+		if err != nil {
+			return model.Game{}, fmt.Errorf("import card: %w", err)
+		}
+	}
+	for i, m := range history.Moves {
+		m.GameID, m.Seq = g.ID, i+1
+		if err := insertMove(ctx, tx, m); err != nil {
+			return model.Game{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Game{}, fmt.Errorf("import game: %w", err)
+	}
+	return g, nil
+}
+
+// insertMove records the move, at m.CreatedAt if set and now otherwise.
+func insertMove(ctx context.Context, tx *sql.Tx, m model.Move) error {
+	var createdAt *string
+	if !m.CreatedAt.IsZero() {
+		at := m.CreatedAt.UTC().Format(timeFormat)
+		createdAt = &at
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO game_moves (game_id, seq, kind, team, player_name, clue, number, position, winner, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, strftime('%Y-%m-%d %H:%M:%f', 'now')))
+	`, m.GameID, m.Seq, m.Kind, m.Team, m.Player, m.Clue, m.Number, m.Position, m.Winner, createdAt)
+	if err != nil {
+		return fmt.Errorf("record move: %w", err)
+	}
+	return nil
+}
+
+func (r *GameRepo) GetCardsByGameID(ctx context.Context, gameID string) ([]model.Card, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, game_id, word, card_type, position, revealed, revealed_by
+		FROM cards WHERE game_id = ? ORDER BY position
+	`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("get cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []model.Card
+	for rows.Next() {
+		var c model.Card
+		if err := rows.Scan(&c.ID, &c.GameID, &c.Word, &c.CardType, &c.Position, &c.Revealed, &c.RevealedBy); err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, rows.Err()
+}
+
+func (r *GameRepo) GetByID(ctx context.Context, id string) (model.Game, error) {
+	var g model.Game
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, room_id, phase, current_team, current_clue, current_number, guesses_left, winner, version
+		FROM games WHERE id = ?
+	`, id).Scan(&g.ID, &g.RoomID, &g.Phase, &g.CurrentTeam, &g.CurrentClue, &g.CurrentNumber, &g.GuessesLeft, &g.Winner, &g.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Game{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return model.Game{}, fmt.Errorf("get game: %w", err)
+	}
+	return g, nil
+}
+
+func (r *GameRepo) GetHistory(ctx context.Context, gameID string) (model.GameHistory, error) {
+	var h model.GameHistory
+	var roster string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT first_team, players, created_at FROM games WHERE id = ?
+	`, gameID).Scan(&h.FirstTeam, &roster, &h.StartedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.GameHistory{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return model.GameHistory{}, fmt.Errorf("get game history: %w", err)
+	}
+	if err := json.Unmarshal([]byte(roster), &h.Players); err != nil {
+		return model.GameHistory{}, fmt.Errorf("decode players: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT game_id, seq, kind, team, player_name, clue, number, position, winner, created_at
+		FROM game_moves WHERE game_id = ? ORDER BY seq
+	`, gameID)
+	if err != nil {
+		return model.GameHistory{}, fmt.Errorf("get moves: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m model.Move
+		if err := rows.Scan(&m.GameID, &m.Seq, &m.Kind, &m.Team, &m.Player, &m.Clue, &m.Number, &m.Position, &m.Winner, &m.CreatedAt); err != nil {
+			return model.GameHistory{}, fmt.Errorf("get moves: %w", err)
+		}
+		h.Moves = append(h.Moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return model.GameHistory{}, fmt.Errorf("get moves: %w", err)
+	}
+	return h, nil
+}
+
+func (r *GameRepo) Deactivate(ctx context.Context, gameID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE games SET phase = 'lobby', version = version + 1 WHERE id = ?
+	`, gameID)
+	return err
+}
@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type HealthRepo struct {
+	db *sql.DB
+}
+
+func NewHealthRepo(db *sql.DB) *HealthRepo {
+	return &HealthRepo{db: db}
+}
+
+func (r *HealthRepo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *HealthRepo) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := r.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
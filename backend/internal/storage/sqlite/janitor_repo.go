@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"codenames/internal/storage"
+)
+
+// timeFormat is how SQLite's strftime writes the timestamps we compare with.
+const timeFormat = "2006-01-02 15:04:05.000"
+
+type JanitorRepo struct {
+	db *sql.DB
+}
+
+func NewJanitorRepo(db *sql.DB) *JanitorRepo {
+	return &JanitorRepo{db: db}
+}
+
+func (r *JanitorRepo) DeleteIdleRooms(ctx context.Context, idleSince time.Time) (storage.PurgeStats, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete idle rooms: %w", err)
+	}
+	defer tx.Rollback()
+
+	idle := `
+		SELECT id FROM rooms r
+		WHERE last_active_at < ?
+			AND NOT EXISTS (SELECT 1 FROM players p WHERE p.room_id = r.id AND p.is_online)
+	`
+	cutoff := idleSince.UTC().Format(timeFormat)
+	var stats storage.PurgeStats
+	err = tx.QueryRowContext(ctx, `
+		WITH idle AS (`+idle+`)
+		SELECT
+			(SELECT COUNT(*) FROM players WHERE room_id IN idle),
+			(SELECT COUNT(*) FROM games WHERE room_id IN idle),
+			(SELECT COUNT(*) FROM cards c JOIN games g ON g.id = c.game_id WHERE g.room_id IN idle),
+			(SELECT COUNT(*) FROM chat_messages WHERE room_id IN idle)
+	`, cutoff).Scan(&stats.Players, &stats.Games, &stats.Cards, &stats.ChatMessages)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("count idle room rows: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM rooms WHERE id IN (`+idle+`)`, cutoff)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete idle rooms: %w", err)
+	}
+	if stats.Rooms, err = res.RowsAffected(); err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete idle rooms: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete idle rooms: %w", err)
+	}
+	return stats, nil
+}
+
+func (r *JanitorRepo) ArchiveGames(ctx context.Context, startedBefore time.Time) (storage.PurgeStats, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("archive games: %w", err)
+	}
+	defer tx.Rollback()
+
+	old := `SELECT id FROM games WHERE phase = 'lobby' AND created_at < ?`
+	cutoff := startedBefore.UTC().Format(timeFormat)
+	var stats storage.PurgeStats
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT game_id), COUNT(*) FROM cards WHERE game_id IN (`+old+`)
+	`, cutoff).Scan(&stats.Games, &stats.Cards)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("archive games: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cards WHERE game_id IN (`+old+`)`, cutoff); err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("archive games: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("archive games: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type PlayerRepo struct {
+	db *sql.DB
+}
+
+func NewPlayerRepo(db *sql.DB) *PlayerRepo {
+	return &PlayerRepo{db: db}
+}
+
+func (r *PlayerRepo) Upsert(ctx context.Context, roomID, sessionID, name string) (model.Player, error) {
+	var p model.Player
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO players (id, room_id, session_id, name, is_online, presence)
+		VALUES (?, ?, ?, ?, 1, 'online')
+		ON CONFLICT (room_id, session_id) DO UPDATE SET name = excluded.name, is_online = 1, presence = 'online'
+		RETURNING id, room_id, session_id, name, team, role, is_online, presence
+	`, storage.NewUUID(), roomID, sessionID, name).Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline, &p.Presence)
+	if err != nil {
+		return model.Player{}, fmt.Errorf("upsert player: %w", err)
+	}
+	if err := touchRoom(ctx, r.db, roomID); err != nil {
+		return model.Player{}, err
+	}
+	return p, nil
+}
+
+func (r *PlayerRepo) GetByRoomID(ctx context.Context, roomID string) ([]model.Player, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, room_id, session_id, name, team, role, is_online, presence
+		FROM players WHERE room_id = ?
+		ORDER BY name
+	`, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("get players: %w", err)
+	}
+	defer rows.Close()
+
+	var players []model.Player
+	for rows.Next() {
+		var p model.Player
+		if err := rows.Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline, &p.Presence); err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	return players, rows.Err()
+}
+
+func (r *PlayerRepo) GetBySessionAndRoom(ctx context.Context, sessionID, roomID string) (model.Player, error) {
+	var p model.Player
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, room_id, session_id, name, team, role, is_online, presence
+		FROM players WHERE session_id = ? AND room_id = ?
+	`, sessionID, roomID).Scan(&p.ID, &p.RoomID, &p.SessionID, &p.Name, &p.Team, &p.Role, &p.IsOnline, &p.Presence)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Player{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return model.Player{}, fmt.Errorf("get player by session: %w", err)
+	}
+	return p, nil
+}
+
+func (r *PlayerRepo) SetTeamRole(ctx context.Context, playerID string, team model.Team, role model.Role) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE players SET team = ?, role = ? WHERE id = ?
+	`, team, role, playerID)
+	return err
+}
+
+// SetPresence also marks the player's room as active, see storage.JanitorStore.
+func (r *PlayerRepo) SetPresence(ctx context.Context, playerID string, presence model.Presence) error {
+	var roomID string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE players SET presence = ?1, is_online = ?1 != 'offline' WHERE id = ?2 RETURNING room_id
+	`, presence, playerID).Scan(&roomID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return touchRoom(ctx, r.db, roomID)
+}
+
+func touchRoom(ctx context.Context, db *sql.DB, roomID string) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE rooms SET last_active_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = ?
+	`, roomID)
+	if err != nil {
+		return fmt.Errorf("touch room: %w", err)
+	}
+	return nil
+}
+
+func (r *PlayerRepo) ResetTeamsAndRoles(ctx context.Context, roomID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE players SET team = '', role = '' WHERE room_id = ?
+	`, roomID)
+	return err
+}
+
+func (r *PlayerRepo) Delete(ctx context.Context, playerID string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM players WHERE id = ?`, playerID)
+	if err != nil {
+		return fmt.Errorf("delete player: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete player: %w", err)
+	}
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"codenames/internal/model"
+)
+
+type RatingRepo struct {
+	db *sql.DB
+}
+
+func NewRatingRepo(db *sql.DB) *RatingRepo {
+	return &RatingRepo{db: db}
+}
+
+func (r *RatingRepo) GetBySessions(ctx context.Context, sessionIDs []string) ([]model.Rating, error) {
+	if len(sessionIDs) == 0 {
+		return []model.Rating{}, nil
+	}
+	args := make([]any, len(sessionIDs))
+	for i, id := range sessionIDs {
+		args[i] = id
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT session_id, player_name, role, rating, games, wins, updated_at
+		FROM ratings WHERE session_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get ratings: %w", err)
+	}
+	defer rows.Close()
+	return scanRatings(rows)
+}
+
+// ApplyGameResult stores the rating changes of a finished game. Changes already
+// recorded for the game are skipped, so applying a result twice is harmless.
+func (r *RatingRepo) ApplyGameResult(ctx context.Context, gameID, roomID string, changes []model.RatingChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("apply game result: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, c := range changes {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO rating_history (session_id, role, game_id, room_id, rating_before, rating_after, won)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (game_id, session_id, role) DO NOTHING
+		`, c.SessionID, c.Role, gameID, roomID, c.Before, c.After, c.Won)
+		if err != nil {
+			return fmt.Errorf("insert rating history: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			continue
+		}
+
+		wins := 0
+		if c.Won {
+			wins = 1
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO ratings (session_id, role, player_name, rating, games, wins)
+			VALUES (?, ?, ?, ?, 1, ?)
+			ON CONFLICT (session_id, role) DO UPDATE SET
+				player_name = excluded.player_name,
+				rating = excluded.rating,
+				games = ratings.games + 1,
+				wins = ratings.wins + excluded.wins,
+				updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+		`, c.SessionID, c.Role, c.PlayerName, c.After, wins)
+		if err != nil {
+			return fmt.Errorf("upsert rating: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Leaderboard returns the highest rated players in a role across all rooms.
+func (r *RatingRepo) Leaderboard(ctx context.Context, role model.Role, limit int) ([]model.Rating, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT session_id, player_name, role, rating, games, wins, updated_at
+		FROM ratings WHERE role = ?
+		ORDER BY rating DESC LIMIT ?
+	`, role, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get leaderboard: %w", err)
+	}
+	defer rows.Close()
+	return scanRatings(rows)
+}
+
+// RoomLeaderboard returns the players who finished a game in the room, with
+// their current rating but games and wins counted in this room only.
+func (r *RatingRepo) RoomLeaderboard(ctx context.Context, roomID string, role model.Role, limit int) ([]model.Rating, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.session_id, r.player_name, r.role, r.rating,
+			COUNT(h.id), SUM(h.won), r.updated_at
+		FROM ratings r
+		JOIN rating_history h ON h.session_id = r.session_id AND h.role = r.role
+		WHERE h.room_id = ? AND r.role = ?
+		GROUP BY r.session_id, r.role
+		ORDER BY r.rating DESC LIMIT ?
+	`, roomID, role, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get room leaderboard: %w", err)
+	}
+	defer rows.Close()
+	return scanRatings(rows)
+}
+
+func scanRatings(rows *sql.Rows) ([]model.Rating, error) {
+	ratings := []model.Rating{}
+	for rows.Next() {
+		var rt model.Rating
+		if err := rows.Scan(&rt.SessionID, &rt.PlayerName, &rt.Role, &rt.Rating, &rt.Games, &rt.Wins, &rt.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, rt)
+	}
+	return ratings, rows.Err()
+}
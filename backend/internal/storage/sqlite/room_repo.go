@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type RoomRepo struct {
+	db *sql.DB
+}
+
+func NewRoomRepo(db *sql.DB) *RoomRepo {
+	return &RoomRepo{db: db}
+}
+
+func (r *RoomRepo) Create(ctx context.Context) (model.Room, error) {
+	id, err := storage.NewRoomID()
+	if err != nil {
+		return model.Room{}, err
+	}
+	settings, err := json.Marshal(model.DefaultRoomSettings())
+	if err != nil {
+		return model.Room{}, fmt.Errorf("encode settings: %w", err)
+	}
+	var room model.Room
+	var raw []byte
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO rooms (id, settings, last_active_at) VALUES (?, ?, strftime('%Y-%m-%d %H:%M:%f', 'now')) RETURNING id, settings, created_at`, id, string(settings),
+	).Scan(&room.ID, &raw, &room.CreatedAt)
+	if err != nil {
+		return model.Room{}, fmt.Errorf("create room: %w", err)
+	}
+	if room.Settings, err = storage.DecodeSettings(raw); err != nil {
+		return model.Room{}, err
+	}
+	return room, nil
+}
+
+func (r *RoomRepo) GetByID(ctx context.Context, id string) (model.Room, error) {
+	var room model.Room
+	var raw []byte
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, settings, created_at FROM rooms WHERE id = ?`, id,
+	).Scan(&room.ID, &raw, &room.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Room{}, fmt.Errorf("get room: %w", storage.ErrNotFound)
+	}
+	if err != nil {
+		return model.Room{}, fmt.Errorf("get room: %w", err)
+	}
+	if room.Settings, err = storage.DecodeSettings(raw); err != nil {
+		return model.Room{}, err
+	}
+	return room, nil
+}
+
+func (r *RoomRepo) UpdateSettings(ctx context.Context, id string, settings model.RoomSettings) error {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("encode settings: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE rooms SET settings = ? WHERE id = ?
+	`, string(raw), id)
+	return err
+}
+
+func (r *RoomRepo) List(ctx context.Context) ([]model.RoomSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r.created_at, r.last_active_at,
+			(SELECT COUNT(*) FROM players p WHERE p.room_id = r.id),
+			(SELECT COUNT(*) FROM players p WHERE p.room_id = r.id AND p.is_online),
+			COALESCE((
+				SELECT g.phase FROM games g WHERE g.room_id = r.id AND g.phase != 'lobby'
+				ORDER BY g.created_at DESC LIMIT 1
+			), 'lobby')
+		FROM rooms r
+		ORDER BY r.last_active_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list rooms: %w", err)
+	}
+	defer rows.Close()
+
+	var rooms []model.RoomSummary
+	for rows.Next() {
+		var s model.RoomSummary
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.LastActiveAt, &s.Players, &s.OnlinePlayers, &s.Phase); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list rooms: %w", err)
+	}
+	return rooms, nil
+}
+
+func (r *RoomRepo) Delete(ctx context.Context, id string) (storage.PurgeStats, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete room: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stats storage.PurgeStats
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM players WHERE room_id = ?1),
+			(SELECT COUNT(*) FROM games WHERE room_id = ?1),
+			(SELECT COUNT(*) FROM cards c JOIN games g ON g.id = c.game_id WHERE g.room_id = ?1),
+			(SELECT COUNT(*) FROM chat_messages WHERE room_id = ?1)
+	`, id).Scan(&stats.Players, &stats.Games, &stats.Cards, &stats.ChatMessages)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("count room rows: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM rooms WHERE id = ?`, id)
+	if err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete room: %w", err)
+	}
+	if stats.Rooms, err = res.RowsAffected(); err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete room: %w", err)
+	}
+	if stats.Rooms == 0 {
+		return storage.PurgeStats{}, storage.ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storage.PurgeStats{}, fmt.Errorf("delete room: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,99 @@
+// Package sqlite implements storage.Store on an SQLite file, for single
+// server installs. Messages between instances stay in process.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"codenames/internal/storage"
+	"codenames/internal/storage/memory"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Scheme is the DATABASE_URL scheme of SQLite databases, e.g.
+// sqlite://codenames.db or sqlite:///var/lib/codenames/codenames.db.
+const Scheme = "sqlite://"
+
+// Open opens the database file named by a sqlite:// URL.
+func Open(ctx context.Context, databaseURL string) (*sql.DB, error) {
+	path, _, _ := strings.Cut(strings.TrimPrefix(databaseURL, Scheme), "?")
+	if path == "" {
+		return nil, errors.New("sqlite: no database file in DATABASE_URL")
+	}
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+	// SQLite has a single writer; one connection avoids busy errors when a
+	// read transaction turns into a write.
+	db.SetMaxOpenConns(1)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("db.Ping: %w", err)
+	}
+	return db, nil
+}
+
+// NewMigrate returns a migrate instance applying the migrations in
+// migrationsPath to db.
+func NewMigrate(db *sql.DB, migrationsPath string) (*migrate.Migrate, error) {
+	driver, err := migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("migrate sqlite: %w", err)
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://"+migrationsPath, "sqlite", driver)
+	if err != nil {
+		return nil, fmt.Errorf("migrate.New: %w", err)
+	}
+	return m, nil
+}
+
+func RunMigrations(db *sql.DB, migrationsPath string) error {
+	m, err := NewMigrate(db, migrationsPath)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("migrate.Up: %w", err)
+	}
+	slog.Info("migrations applied")
+	return nil
+}
+
+// NewStore returns a Store backed by db.
+func NewStore(db *sql.DB) storage.Store {
+	return storage.Store{
+		Rooms:    NewRoomRepo(db),
+		Players:  NewPlayerRepo(db),
+		Games:    NewGameRepo(db),
+		Chat:     NewChatRepo(db),
+		Ratings:  NewRatingRepo(db),
+		Users:    NewUserRepo(db),
+		Janitor:  NewJanitorRepo(db),
+		Notifier: memory.NewNotifier(),
+		Health:   NewHealthRepo(db),
+
+		WordPacks: NewWordPackRepo(db),
+		Stats:     NewStatsRepo(db),
+	}
+}
+
+// uniqueViolation reports whether err is a UNIQUE constraint failure on the
+// given table.column.
+func uniqueViolation(err error, column string) bool {
+	var sqlErr *sqlitedriver.Error
+	return errors.As(err, &sqlErr) &&
+		sqlErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE &&
+		strings.Contains(sqlErr.Error(), column)
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"codenames/internal/storage"
+	"codenames/internal/storage/storagetest"
+)
+
+func TestStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		db, err := Open(context.Background(), Scheme+filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if err := RunMigrations(db, "../../../migrations/sqlite"); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		return NewStore(db)
+	})
+}
+
+// The migrations must also roll back cleanly.
+func TestMigrationsDown(t *testing.T) {
+	db, err := Open(context.Background(), Scheme+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	m, err := NewMigrate(db, "../../../migrations/sqlite")
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if err := m.Down(); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"codenames/internal/storage"
+)
+
+type StatsRepo struct {
+	db *sql.DB
+}
+
+func NewStatsRepo(db *sql.DB) *StatsRepo {
+	return &StatsRepo{db: db}
+}
+
+func (r *StatsRepo) Usage(ctx context.Context, since time.Time) (storage.UsageStats, error) {
+	var s storage.UsageStats
+	cutoff := since.UTC().Format(timeFormat)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT count(*) FROM rooms),
+			(SELECT count(DISTINCT room_id) FROM players WHERE is_online),
+			(SELECT count(*) FROM rooms WHERE created_at >= ?1),
+			(SELECT count(*) FROM players),
+			(SELECT count(*) FROM players WHERE is_online),
+			(SELECT count(*) FROM users),
+			(SELECT count(*) FROM games),
+			(SELECT count(*) FROM games WHERE phase = 'playing'),
+			(SELECT count(*) FROM games WHERE created_at >= ?1),
+			(SELECT count(*) FROM chat_messages),
+			(SELECT count(*) FROM word_packs)
+	`, cutoff).Scan(&s.Rooms, &s.ActiveRooms, &s.RoomsSince, &s.Players, &s.OnlinePlayers, &s.Users,
+		&s.Games, &s.GamesPlaying, &s.GamesSince, &s.ChatMessages, &s.WordPacks)
+	if err != nil {
+		return storage.UsageStats{}, fmt.Errorf("count usage: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+type UserRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) Create(ctx context.Context, username, passwordHash, sessionID string) (model.User, error) {
+	var u model.User
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO users (id, username, password_hash, session_id)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, username, password_hash, session_id, created_at
+	`, storage.NewUUID(), username, passwordHash, sessionID).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SessionID, &u.CreatedAt)
+	if err != nil {
+		switch {
+		case uniqueViolation(err, "users.session_id"):
+			return model.User{}, storage.ErrSessionTaken
+		case uniqueViolation(err, "users.username"):
+			return model.User{}, storage.ErrUsernameTaken
+		}
+		return model.User{}, fmt.Errorf("create user: %w", err)
+	}
+	return u, nil
+}
+
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	var u model.User
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, username, password_hash, session_id, created_at
+		FROM users WHERE username = ?
+	`, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SessionID, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("get user: %w", err)
+	}
+	return u, nil
+}
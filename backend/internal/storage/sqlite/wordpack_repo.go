@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"codenames/internal/model"
+)
+
+type WordPackRepo struct {
+	db *sql.DB
+}
+
+func NewWordPackRepo(db *sql.DB) *WordPackRepo {
+	return &WordPackRepo{db: db}
+}
+
+func (r *WordPackRepo) Save(ctx context.Context, name string, pack model.WordPack) error {
+	words, err := json.Marshal(pack.Words)
+	if err != nil {
+		return fmt.Errorf("encode words: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO word_packs (name, language, words) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET language = excluded.language, words = excluded.words,
+			created_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+	`, name, pack.Language, string(words))
+	if err != nil {
+		return fmt.Errorf("save word pack: %w", err)
+	}
+	return nil
+}
+
+func (r *WordPackRepo) List(ctx context.Context) (map[string]model.WordPack, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT name, language, words FROM word_packs`)
+	if err != nil {
+		return nil, fmt.Errorf("list word packs: %w", err)
+	}
+	defer rows.Close()
+
+	packs := make(map[string]model.WordPack)
+	for rows.Next() {
+		var name, words string
+		var pack model.WordPack
+		if err := rows.Scan(&name, &pack.Language, &words); err != nil {
+			return nil, fmt.Errorf("list word packs: %w", err)
+		}
+		if err := json.Unmarshal([]byte(words), &pack.Words); err != nil {
+			return nil, fmt.Errorf("decode word pack %s: %w", name, err)
+		}
+		packs[name] = pack
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list word packs: %w", err)
+	}
+	return packs, nil
+}
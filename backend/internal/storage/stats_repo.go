@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StatsRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatsRepo(pool *pgxpool.Pool) *StatsRepo {
+	return &StatsRepo{pool: pool}
+}
+
+func (r *StatsRepo) Usage(ctx context.Context, since time.Time) (UsageStats, error) {
+	var s UsageStats
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			(SELECT count(*) FROM rooms),
+			(SELECT count(DISTINCT room_id) FROM players WHERE is_online),
+			(SELECT count(*) FROM rooms WHERE created_at >= $1),
+			(SELECT count(*) FROM players),
+			(SELECT count(*) FROM players WHERE is_online),
+			(SELECT count(*) FROM users),
+			(SELECT count(*) FROM games),
+			(SELECT count(*) FROM games WHERE phase = 'playing'),
+			(SELECT count(*) FROM games WHERE created_at >= $1),
+			(SELECT count(*) FROM chat_messages),
+			(SELECT count(*) FROM word_packs)
+	`, since).Scan(&s.Rooms, &s.ActiveRooms, &s.RoomsSince, &s.Players, &s.OnlinePlayers, &s.Users,
+		&s.Games, &s.GamesPlaying, &s.GamesSince, &s.ChatMessages, &s.WordPacks)
+	if err != nil {
+		return UsageStats{}, fmt.Errorf("count usage: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,173 @@
+// Package storagetest holds tests every storage backend must pass, run by
+// each backend's own tests so they behave alike.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"codenames/internal/model"
+	"codenames/internal/storage"
+)
+
+// Run runs every test against the store made by newStore, a fresh one for
+// each test.
+func Run(t *testing.T, newStore func(t *testing.T) storage.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s storage.Store)
+	}{
+		{"RatingsSurvivePurge", testRatingsSurvivePurge},
+		{"PlayerNotFound", testPlayerNotFound},
+		{"SaveConflict", testSaveConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// newBoard returns the cards of a small board: red, blue, then neutral.
+func newBoard(size int) []model.Card {
+	cards := make([]model.Card, size)
+	for i := range cards {
+		cards[i] = model.Card{Position: i, Word: "W" + string(rune('A'+i)), CardType: model.CardTypeNeutral}
+	}
+	cards[0].CardType = model.CardTypeRed
+	cards[1].CardType = model.CardTypeBlue
+	return cards
+}
+
+func testRatingsSurvivePurge(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	room, err := s.Rooms.Create(ctx)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	g, _, err := s.Games.CreateWithCards(ctx, room.ID, model.TeamRed, newBoard(16), nil)
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	changes := []model.RatingChange{
+		{SessionID: "s1", PlayerName: "Аня", Role: model.RoleSpymaster, Before: 1000, After: 1016, Won: true},
+		{SessionID: "s2", PlayerName: "Боря", Role: model.RoleSpymaster, Before: 1000, After: 984},
+	}
+	if err := s.Ratings.ApplyGameResult(ctx, g.ID, room.ID, changes); err != nil {
+		t.Fatalf("apply game result: %v", err)
+	}
+
+	stats, err := s.Janitor.DeleteIdleRooms(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("delete idle rooms: %v", err)
+	}
+	if stats.Rooms != 1 || stats.Games != 1 {
+		t.Fatalf("purged %d rooms and %d games, want 1 and 1", stats.Rooms, stats.Games)
+	}
+
+	all, err := s.Ratings.Leaderboard(ctx, model.RoleSpymaster, 10)
+	if err != nil {
+		t.Fatalf("leaderboard: %v", err)
+	}
+	if len(all) != 2 || all[0].SessionID != "s1" || all[0].Rating != 1016 {
+		t.Errorf("leaderboard after purge = %+v, want s1 at 1016 then s2", all)
+	}
+	inRoom, err := s.Ratings.RoomLeaderboard(ctx, room.ID, model.RoleSpymaster, 10)
+	if err != nil {
+		t.Fatalf("room leaderboard: %v", err)
+	}
+	if len(inRoom) != 2 || inRoom[0].Games != 1 || inRoom[0].Wins != 1 {
+		t.Errorf("room leaderboard after purge = %+v, want 2 players with their game", inRoom)
+	}
+}
+
+func testPlayerNotFound(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	room, err := s.Rooms.Create(ctx)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if _, err := s.Players.Upsert(ctx, room.ID, "s1", "Аня"); err != nil {
+		t.Fatalf("upsert player: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		sessionID string
+		roomID    string
+		wantErr   error
+	}{
+		{"player in room", "s1", room.ID, nil},
+		{"unknown session", "s2", room.ID, storage.ErrNotFound},
+		{"unknown room", "s1", "deadbeef", storage.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := s.Players.GetBySessionAndRoom(ctx, tt.sessionID, tt.roomID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("get player: err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && p.Name != "Аня" {
+				t.Errorf("got player %q, want Аня", p.Name)
+			}
+		})
+	}
+}
+
+// testSaveConflict saves moves at the game's version and at stale ones.
+// Conflicting saves must change nothing.
+func testSaveConflict(t *testing.T, s storage.Store) {
+	ctx := context.Background()
+	room, err := s.Rooms.Create(ctx)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	g, cards, err := s.Games.CreateWithCards(ctx, room.ID, model.TeamRed, newBoard(16), nil)
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	start := g.Version
+	revealed := cards[0]
+	revealed.Revealed, revealed.RevealedBy = true, model.TeamRed
+
+	steps := []struct {
+		name     string
+		version  int
+		move     model.Move
+		revealed []model.Card
+		wantErr  error
+	}{
+		{"clue", start, model.Move{Kind: model.MoveClue, Team: model.TeamRed, Clue: "ШПИОН", Number: 1}, nil, nil},
+		{"stale clue", start, model.Move{Kind: model.MoveClue, Team: model.TeamRed, Clue: "ДРУГОЙ", Number: 1}, nil, storage.ErrConflict},
+		{"guess", start + 1, model.Move{Kind: model.MoveGuess, Team: model.TeamRed, Position: 0}, []model.Card{revealed}, nil},
+		{"stale guess", start + 1, model.Move{Kind: model.MoveGuess, Team: model.TeamRed, Position: 1}, nil, storage.ErrConflict},
+		{"card revealed twice", start + 2, model.Move{Kind: model.MoveGuess, Team: model.TeamRed, Position: 0}, []model.Card{revealed}, storage.ErrConflict},
+	}
+	for _, st := range steps {
+		g.Version = st.version
+		version, err := s.Games.Save(ctx, g, st.move, st.revealed)
+		if !errors.Is(err, st.wantErr) {
+			t.Fatalf("%s: save err = %v, want %v", st.name, err, st.wantErr)
+		}
+		if err == nil && version != st.version+1 {
+			t.Errorf("%s: saved at version %d, want %d", st.name, version, st.version+1)
+		}
+	}
+
+	saved, err := s.Games.GetByID(ctx, g.ID)
+	if err != nil {
+		t.Fatalf("get game: %v", err)
+	}
+	if saved.Version != start+2 {
+		t.Errorf("game is at version %d, want %d", saved.Version, start+2)
+	}
+	history, err := s.Games.GetHistory(ctx, g.ID)
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(history.Moves) != 2 || history.Moves[0].Clue != "ШПИОН" || history.Moves[1].Position != 0 {
+		t.Errorf("history = %+v, want the clue and the guess", history.Moves)
+	}
+}
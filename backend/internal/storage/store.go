@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"codenames/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store is a complete storage backend. The repos in this package implement
+// it on Postgres; packages sqlite and memory implement it on an SQLite file
+// and in process.
+type Store struct {
+	Rooms    RoomStore
+	Players  PlayerStore
+	Games    GameStore
+	Chat     ChatStore
+	Ratings  RatingStore
+	Users    UserStore
+	Janitor  JanitorStore
+	Notifier Notifier
+	Health   HealthStore
+
+	WordPacks WordPackStore
+	Stats     StatsStore
+}
+
+// NewPostgresStore returns a Store backed by the pool.
+func NewPostgresStore(pool *pgxpool.Pool) Store {
+	return Store{
+		Rooms:    NewRoomRepo(pool),
+		Players:  NewPlayerRepo(pool),
+		Games:    NewGameRepo(pool),
+		Chat:     NewChatRepo(pool),
+		Ratings:  NewRatingRepo(pool),
+		Users:    NewUserRepo(pool),
+		Janitor:  NewJanitorRepo(pool),
+		Notifier: NewPostgresNotifier(pool),
+		Health:   NewHealthRepo(pool),
+
+		WordPacks: NewWordPackRepo(pool),
+		Stats:     NewStatsRepo(pool),
+	}
+}
+
+type RoomStore interface {
+	Create(ctx context.Context) (model.Room, error)
+	GetByID(ctx context.Context, id string) (model.Room, error)
+	UpdateSettings(ctx context.Context, id string, settings model.RoomSettings) error
+	// List returns every room, most recently active first.
+	List(ctx context.Context) ([]model.RoomSummary, error)
+	// Delete deletes the room with everything in it. It returns ErrNotFound
+	// for an unknown room.
+	Delete(ctx context.Context, id string) (PurgeStats, error)
+}
+
+type PlayerStore interface {
+	Upsert(ctx context.Context, roomID, sessionID, name string) (model.Player, error)
+	GetByRoomID(ctx context.Context, roomID string) ([]model.Player, error)
+	GetBySessionAndRoom(ctx context.Context, sessionID, roomID string) (model.Player, error)
+	SetTeamRole(ctx context.Context, playerID string, team model.Team, role model.Role) error
+	// SetPresence also sets IsOnline, which is false only when offline.
+	SetPresence(ctx context.Context, playerID string, presence model.Presence) error
+	ResetTeamsAndRoles(ctx context.Context, roomID string) error
+	// Delete removes the player from their room. It returns ErrNotFound for
+	// an unknown player.
+	Delete(ctx context.Context, playerID string) error
+}
+
+type GameStore interface {
+	// CreateWithCards starts a game with the players as they are now.
+	CreateWithCards(ctx context.Context, roomID string, firstTeam model.Team, cards []model.Card, players []model.GamePlayer) (model.Game, []model.Card, error)
+	// Import creates a game played elsewhere, in the state its history led
+	// to, with the history's moves numbered from 1.
+	Import(ctx context.Context, roomID string, g model.Game, cards []model.Card, history model.GameHistory) (model.Game, error)
+	// GetActiveByRoomID returns ErrNotFound when the room is in the lobby.
+	GetActiveByRoomID(ctx context.Context, roomID string) (model.Game, error)
+	// Save records the move and returns ErrConflict when the game is no
+	// longer at g.Version.
+	Save(ctx context.Context, g model.Game, move model.Move, revealed []model.Card) (int, error)
+	GetCardsByGameID(ctx context.Context, gameID string) ([]model.Card, error)
+	// GetByID returns ErrNotFound for an unknown game.
+	GetByID(ctx context.Context, id string) (model.Game, error)
+	// GetHistory returns ErrNotFound for an unknown game.
+	GetHistory(ctx context.Context, gameID string) (model.GameHistory, error)
+	Deactivate(ctx context.Context, gameID string) error
+}
+
+type ChatStore interface {
+	Create(ctx context.Context, m model.ChatMessage) (model.ChatMessage, error)
+	GetHistory(ctx context.Context, roomID string, channel model.ChatChannel, team model.Team, beforeID int64, limit int) ([]model.ChatMessage, error)
+}
+
+type RatingStore interface {
+	GetBySessions(ctx context.Context, sessionIDs []string) ([]model.Rating, error)
+	ApplyGameResult(ctx context.Context, gameID, roomID string, changes []model.RatingChange) error
+	Leaderboard(ctx context.Context, role model.Role, limit int) ([]model.Rating, error)
+	RoomLeaderboard(ctx context.Context, roomID string, role model.Role, limit int) ([]model.Rating, error)
+}
+
+type UserStore interface {
+	// Create returns ErrUsernameTaken or ErrSessionTaken for duplicates.
+	Create(ctx context.Context, username, passwordHash, sessionID string) (model.User, error)
+	// GetByUsername returns ErrNotFound for an unknown username.
+	GetByUsername(ctx context.Context, username string) (model.User, error)
+}
+
+// JanitorStore removes data nobody needs any more.
+type JanitorStore interface {
+	// DeleteIdleRooms deletes the rooms without online players that had no
+	// player join, connect or disconnect since idleSince, with everything
+	// in them but the rating history, which the leaderboards are made of.
+	DeleteIdleRooms(ctx context.Context, idleSince time.Time) (PurgeStats, error)
+	// ArchiveGames deletes the boards of games that were replaced by a newer
+	// game and started before startedBefore. The games themselves are kept
+	// for their results.
+	ArchiveGames(ctx context.Context, startedBefore time.Time) (PurgeStats, error)
+}
+
+// PurgeStats counts the rows a cleanup removed.
+type PurgeStats struct {
+	Rooms        int64 `json:"rooms"`
+	Players      int64 `json:"players"`
+	Games        int64 `json:"games"`
+	Cards        int64 `json:"cards"`
+	ChatMessages int64 `json:"chat_messages"`
+}
+
+func (s *PurgeStats) Add(o PurgeStats) {
+	s.Rooms += o.Rooms
+	s.Players += o.Players
+	s.Games += o.Games
+	s.Cards += o.Cards
+	s.ChatMessages += o.ChatMessages
+}
+
+// WordPackStore holds the word packs imported by operators.
+type WordPackStore interface {
+	// Save creates the pack or replaces the one with the same name.
+	Save(ctx context.Context, name string, pack model.WordPack) error
+	// List returns the packs by name.
+	List(ctx context.Context) (map[string]model.WordPack, error)
+}
+
+// StatsStore counts what the store holds.
+type StatsStore interface {
+	// Usage counts everything, and the rooms and games created since.
+	Usage(ctx context.Context, since time.Time) (UsageStats, error)
+}
+
+// UsageStats describes how much the server is used. Active rooms have
+// online players.
+type UsageStats struct {
+	Rooms         int64 `json:"rooms"`
+	ActiveRooms   int64 `json:"active_rooms"`
+	RoomsSince    int64 `json:"rooms_since"`
+	Players       int64 `json:"players"`
+	OnlinePlayers int64 `json:"online_players"`
+	Users         int64 `json:"users"`
+	Games         int64 `json:"games"`
+	GamesPlaying  int64 `json:"games_playing"`
+	GamesSince    int64 `json:"games_since"`
+	ChatMessages  int64 `json:"chat_messages"`
+	WordPacks     int64 `json:"word_packs"`
+}
+
+// HealthStore reports whether the database can be used.
+type HealthStore interface {
+	Ping(ctx context.Context) error
+	// SchemaVersion returns the version of the last migration applied and
+	// whether it failed half-way. It is 0 for a store without migrations.
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// Notifier passes messages between the server instances sharing a store.
+type Notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
+	// Listen calls handle for every message on channel, in order, until ctx is done.
+	Listen(ctx context.Context, channel string, handle func(payload string))
+}
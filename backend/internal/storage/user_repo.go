@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"codenames/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserRepo(pool *pgxpool.Pool) *UserRepo {
+	return &UserRepo{pool: pool}
+}
+
+func (r *UserRepo) Create(ctx context.Context, username, passwordHash, sessionID string) (model.User, error) {
+	var u model.User
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO users (username, password_hash, session_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, username, password_hash, session_id, created_at
+	`, username, passwordHash, sessionID).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SessionID, &u.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "users_session_id_key" {
+				return model.User{}, ErrSessionTaken
+			}
+			return model.User{}, ErrUsernameTaken
+		}
+		return model.User{}, fmt.Errorf("create user: %w", err)
+	}
+	return u, nil
+}
+
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	var u model.User
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, username, password_hash, session_id, created_at
+		FROM users WHERE username = $1
+	`, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.SessionID, &u.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.User{}, ErrNotFound
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("get user: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"codenames/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WordPackRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewWordPackRepo(pool *pgxpool.Pool) *WordPackRepo {
+	return &WordPackRepo{pool: pool}
+}
+
+func (r *WordPackRepo) Save(ctx context.Context, name string, pack model.WordPack) error {
+	words, err := json.Marshal(pack.Words)
+	if err != nil {
+		return fmt.Errorf("encode words: %w", err)
+	}
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO word_packs (name, language, words) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET language = EXCLUDED.language, words = EXCLUDED.words, created_at = now()
+	`, name, pack.Language, words)
+	if err != nil {
+		return fmt.Errorf("save word pack: %w", err)
+	}
+	return nil
+}
+
+func (r *WordPackRepo) List(ctx context.Context) (map[string]model.WordPack, error) {
+	rows, err := r.pool.Query(ctx, `SELECT name, language, words FROM word_packs`)
+	if err != nil {
+		return nil, fmt.Errorf("list word packs: %w", err)
+	}
+	defer rows.Close()
+
+	packs := make(map[string]model.WordPack)
+	for rows.Next() {
+		var name string
+		var pack model.WordPack
+		var words []byte
+		if err := rows.Scan(&name, &pack.Language, &words); err != nil {
+			return nil, fmt.Errorf("list word packs: %w", err)
+		}
+		if err := json.Unmarshal(words, &pack.Words); err != nil {
+			return nil, fmt.Errorf("decode word pack %s: %w", name, err)
+		}
+		packs[name] = pack
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list word packs: %w", err)
+	}
+	return packs, nil
+}
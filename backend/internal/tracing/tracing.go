@@ -0,0 +1,62 @@
+// Package tracing sets up OpenTelemetry tracing.
+//
+// The exporter is chosen by OTEL_TRACES_EXPORTER: "otlp" sends spans over
+// OTLP/HTTP to the collector named by the standard OTEL_EXPORTER_OTLP_*
+// variables, "console" writes them to stdout and "none", the default,
+// turns tracing off. OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES
+// describe the service as usual.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters.
+const (
+	ExporterNone    = "none"
+	ExporterConsole = "console"
+	ExporterOTLP    = "otlp"
+)
+
+// Setup installs the global tracer provider and propagator. The returned
+// function flushes and stops the exporter.
+func Setup(ctx context.Context, exporter string) (shutdown func(context.Context) error, err error) {
+	var exp sdktrace.SpanExporter
+	switch exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterConsole:
+		exp, err = stdouttrace.New()
+	case ExporterOTLP:
+		exp, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q, want %s, %s or %s", exporter, ExporterOTLP, ExporterConsole, ExporterNone)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName("codenames")))
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+	// Environment variables override the default service name.
+	res, err = resource.Merge(res, resource.Environment())
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
@@ -0,0 +1 @@
+ALTER TABLE rooms ADD COLUMN settings JSONB NOT NULL DEFAULT '{}';
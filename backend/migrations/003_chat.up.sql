@@ -0,0 +1,12 @@
+CREATE TABLE chat_messages (
+    id BIGSERIAL PRIMARY KEY,
+    room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    player_id UUID REFERENCES players(id) ON DELETE SET NULL,
+    player_name TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    team TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_chat_messages_room_id ON chat_messages(room_id, id);
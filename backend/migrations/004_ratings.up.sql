@@ -0,0 +1,28 @@
+CREATE TABLE ratings (
+    session_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    player_name TEXT NOT NULL,
+    rating DOUBLE PRECISION NOT NULL,
+    games INT NOT NULL DEFAULT 0,
+    wins INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (session_id, role)
+);
+
+CREATE INDEX idx_ratings_role_rating ON ratings(role, rating DESC);
+
+CREATE TABLE rating_history (
+    id BIGSERIAL PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    rating_before DOUBLE PRECISION NOT NULL,
+    rating_after DOUBLE PRECISION NOT NULL,
+    won BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(game_id, session_id, role)
+);
+
+CREATE INDEX idx_rating_history_session ON rating_history(session_id, role);
+CREATE INDEX idx_rating_history_room_id ON rating_history(room_id);
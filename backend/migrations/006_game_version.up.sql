@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN version INT NOT NULL DEFAULT 0;
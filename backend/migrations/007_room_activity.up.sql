@@ -0,0 +1,3 @@
+ALTER TABLE rooms ADD COLUMN last_active_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX idx_rooms_last_active_at ON rooms(last_active_at);
+CREATE INDEX idx_games_phase_created_at ON games(phase, created_at);
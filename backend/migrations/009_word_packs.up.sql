@@ -0,0 +1,6 @@
+CREATE TABLE word_packs (
+    name TEXT PRIMARY KEY,
+    language TEXT NOT NULL,
+    words JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
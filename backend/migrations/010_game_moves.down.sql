@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS game_moves;
+ALTER TABLE games DROP COLUMN IF EXISTS players;
+ALTER TABLE games DROP COLUMN IF EXISTS first_team;
@@ -0,0 +1,16 @@
+ALTER TABLE games ADD COLUMN first_team TEXT NOT NULL DEFAULT '';
+ALTER TABLE games ADD COLUMN players JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE game_moves (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    seq INT NOT NULL,
+    kind TEXT NOT NULL,
+    team TEXT NOT NULL,
+    player_name TEXT NOT NULL DEFAULT '',
+    clue TEXT NOT NULL DEFAULT '',
+    number INT NOT NULL DEFAULT 0,
+    position INT NOT NULL DEFAULT 0,
+    winner TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (game_id, seq)
+);
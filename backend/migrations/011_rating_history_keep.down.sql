@@ -0,0 +1,5 @@
+DELETE FROM rating_history h
+WHERE NOT EXISTS (SELECT 1 FROM games g WHERE g.id = h.game_id)
+    OR NOT EXISTS (SELECT 1 FROM rooms r WHERE r.id = h.room_id);
+ALTER TABLE rating_history ADD CONSTRAINT rating_history_game_id_fkey FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE;
+ALTER TABLE rating_history ADD CONSTRAINT rating_history_room_id_fkey FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+-- Rating history outlives the rooms and games it was earned in, which the
+-- janitor deletes.
+ALTER TABLE rating_history DROP CONSTRAINT rating_history_game_id_fkey;
+ALTER TABLE rating_history DROP CONSTRAINT rating_history_room_id_fkey;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS rating_history;
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS chat_messages;
+DROP TABLE IF EXISTS cards;
+DROP TABLE IF EXISTS players;
+DROP TABLE IF EXISTS games;
+DROP TABLE IF EXISTS rooms;
@@ -0,0 +1,95 @@
+CREATE TABLE rooms (
+    id TEXT PRIMARY KEY,
+    settings TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE games (
+    id TEXT PRIMARY KEY,
+    room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    phase TEXT NOT NULL DEFAULT 'lobby',
+    current_team TEXT NOT NULL DEFAULT 'red',
+    current_clue TEXT NOT NULL DEFAULT '',
+    current_number INTEGER NOT NULL DEFAULT 0,
+    guesses_left INTEGER NOT NULL DEFAULT 0,
+    winner TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX idx_games_room_id ON games(room_id);
+
+CREATE TABLE players (
+    id TEXT PRIMARY KEY,
+    room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    team TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT '',
+    is_online BOOLEAN NOT NULL DEFAULT 1,
+    UNIQUE(room_id, session_id)
+);
+
+CREATE INDEX idx_players_session_id ON players(session_id);
+
+CREATE TABLE cards (
+    id TEXT PRIMARY KEY,
+    game_id TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    word TEXT NOT NULL,
+    card_type TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    revealed BOOLEAN NOT NULL DEFAULT 0,
+    revealed_by TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_cards_game_id ON cards(game_id);
+
+CREATE TABLE chat_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    player_id TEXT REFERENCES players(id) ON DELETE SET NULL,
+    player_name TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    team TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX idx_chat_messages_room_id ON chat_messages(room_id, id);
+
+CREATE TABLE ratings (
+    session_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    player_name TEXT NOT NULL,
+    rating REAL NOT NULL,
+    games INTEGER NOT NULL DEFAULT 0,
+    wins INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (session_id, role)
+);
+
+CREATE INDEX idx_ratings_role_rating ON ratings(role, rating DESC);
+
+CREATE TABLE rating_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    game_id TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    rating_before REAL NOT NULL,
+    rating_after REAL NOT NULL,
+    won BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    UNIQUE(game_id, session_id, role)
+);
+
+CREATE INDEX idx_rating_history_session ON rating_history(session_id, role);
+CREATE INDEX idx_rating_history_room_id ON rating_history(room_id);
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    session_id TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_games_phase_created_at;
+DROP INDEX IF EXISTS idx_rooms_last_active_at;
+ALTER TABLE rooms DROP COLUMN last_active_at;
@@ -0,0 +1,4 @@
+ALTER TABLE rooms ADD COLUMN last_active_at TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00.000';
+UPDATE rooms SET last_active_at = strftime('%Y-%m-%d %H:%M:%f', 'now');
+CREATE INDEX idx_rooms_last_active_at ON rooms(last_active_at);
+CREATE INDEX idx_games_phase_created_at ON games(phase, created_at);
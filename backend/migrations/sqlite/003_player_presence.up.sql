@@ -0,0 +1,2 @@
+ALTER TABLE players ADD COLUMN presence TEXT NOT NULL DEFAULT 'online';
+UPDATE players SET presence = 'offline' WHERE NOT is_online;
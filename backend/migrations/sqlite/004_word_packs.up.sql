@@ -0,0 +1,6 @@
+CREATE TABLE word_packs (
+    name TEXT PRIMARY KEY,
+    language TEXT NOT NULL,
+    words TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
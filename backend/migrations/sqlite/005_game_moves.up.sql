@@ -0,0 +1,16 @@
+ALTER TABLE games ADD COLUMN first_team TEXT NOT NULL DEFAULT '';
+ALTER TABLE games ADD COLUMN players TEXT NOT NULL DEFAULT '[]';
+
+CREATE TABLE game_moves (
+    game_id TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    team TEXT NOT NULL,
+    player_name TEXT NOT NULL DEFAULT '',
+    clue TEXT NOT NULL DEFAULT '',
+    number INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0,
+    winner TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (game_id, seq)
+);
@@ -0,0 +1,21 @@
+CREATE TABLE rating_history_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    game_id TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    rating_before REAL NOT NULL,
+    rating_after REAL NOT NULL,
+    won BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    UNIQUE(game_id, session_id, role)
+);
+
+INSERT INTO rating_history_new SELECT * FROM rating_history h
+WHERE EXISTS (SELECT 1 FROM games g WHERE g.id = h.game_id)
+    AND EXISTS (SELECT 1 FROM rooms r WHERE r.id = h.room_id);
+DROP TABLE rating_history;
+ALTER TABLE rating_history_new RENAME TO rating_history;
+
+CREATE INDEX idx_rating_history_session ON rating_history(session_id, role);
+CREATE INDEX idx_rating_history_room_id ON rating_history(room_id);
@@ -0,0 +1,21 @@
+-- Rating history outlives the rooms and games it was earned in, which the
+-- janitor deletes. SQLite can't drop a foreign key, so the table is rebuilt.
+CREATE TABLE rating_history_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    game_id TEXT NOT NULL,
+    room_id TEXT NOT NULL,
+    rating_before REAL NOT NULL,
+    rating_after REAL NOT NULL,
+    won BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    UNIQUE(game_id, session_id, role)
+);
+
+INSERT INTO rating_history_new SELECT * FROM rating_history;
+DROP TABLE rating_history;
+ALTER TABLE rating_history_new RENAME TO rating_history;
+
+CREATE INDEX idx_rating_history_session ON rating_history(session_id, role);
+CREATE INDEX idx_rating_history_room_id ON rating_history(room_id);
@@ -1,45 +1,134 @@
 const API_BASE = import.meta.env.VITE_API_URL || '';
 
-function getSessionID(): string {
-  let sid = localStorage.getItem('session_id');
-  if (!sid) {
-    sid = 'xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx'.replace(/[xy]/g, (c) => {
-      const r = (crypto.getRandomValues(new Uint8Array(1))[0] & 15);
-      const v = c === 'x' ? r : (r & 0x3) | 0x8;
-      return v.toString(16);
-    });
-    localStorage.setItem('session_id', sid);
+function getToken(): string {
+  return localStorage.getItem('token') ?? '';
+}
+
+export { getToken };
+
+// rateLimitedMessage tells a rate limited player how long to wait.
+export function rateLimitedMessage(retryAfterSeconds?: number): string {
+  if (!retryAfterSeconds || retryAfterSeconds < 0) {
+    return 'Слишком много запросов, попробуйте чуть позже';
+  }
+  return `Слишком много запросов, попробуйте через ${Math.ceil(retryAfterSeconds)} с`;
+}
+
+// requestError describes a failed response, telling rate limited clients
+// when to retry.
+function requestError(res: Response, fallback: string): Error {
+  if (res.status === 429) {
+    return new Error(rateLimitedMessage(Number(res.headers.get('Retry-After'))));
   }
-  return sid;
+  return new Error(fallback);
+}
+
+// ensureToken returns the stored token, starting a guest session if there is none.
+async function ensureToken(): Promise<string> {
+  const token = getToken();
+  if (token) return token;
+  const res = await fetch(`${API_BASE}/api/auth/guest`, { method: 'POST' });
+  if (!res.ok) throw requestError(res, 'Не удалось начать сеанс');
+  const body: { token: string } = await res.json();
+  localStorage.setItem('token', body.token);
+  return body.token;
 }
 
-export { getSessionID };
+async function authenticate(path: string, username: string, password: string): Promise<void> {
+  const headers: Record<string, string> = { 'Content-Type': 'application/json' };
+  const token = getToken();
+  if (token) headers['Authorization'] = `Bearer ${token}`;
+  const res = await fetch(`${API_BASE}/api/auth/${path}`, {
+    method: 'POST',
+    headers,
+    body: JSON.stringify({ username, password }),
+  });
+  if (!res.ok) throw requestError(res, await res.text());
+  const body: { token: string } = await res.json();
+  localStorage.setItem('token', body.token);
+}
+
+// register upgrades the current guest session to an account.
+export function register(username: string, password: string): Promise<void> {
+  return authenticate('register', username, password);
+}
+
+export function login(username: string, password: string): Promise<void> {
+  return authenticate('login', username, password);
+}
 
 export async function createRoom(): Promise<{ id: string }> {
+  const token = getToken();
   const res = await fetch(`${API_BASE}/api/rooms`, {
     method: 'POST',
-    headers: { 'X-Session-ID': getSessionID() },
+    headers: token ? { 'Authorization': `Bearer ${token}` } : {},
   });
-  if (!res.ok) throw new Error('Failed to create room');
+  if (!res.ok) throw requestError(res, 'Не удалось создать комнату');
   return res.json();
 }
 
 export async function joinRoom(roomID: string, name: string): Promise<void> {
+  const token = await ensureToken();
   const res = await fetch(`${API_BASE}/api/players`, {
     method: 'POST',
     headers: {
       'Content-Type': 'application/json',
-      'X-Session-ID': getSessionID(),
+      'Authorization': `Bearer ${token}`,
     },
     body: JSON.stringify({ room_id: roomID, name }),
   });
-  if (!res.ok) throw new Error('Failed to join room');
+  if (res.status === 401) localStorage.removeItem('token');
+  if (!res.ok) throw requestError(res, 'Не удалось войти в комнату');
 }
 
 export async function getRoomState(roomID: string) {
-  const res = await fetch(`${API_BASE}/api/rooms/${roomID}`, {
-    headers: { 'X-Session-ID': getSessionID() },
-  });
+  const res = await fetch(`${API_BASE}/api/rooms/${roomID}`);
   if (!res.ok) throw new Error('Room not found');
   return res.json();
 }
+
+// downloadGame saves the record of a finished game. Only players of its
+// room may get it, so the file is fetched with the token.
+export async function downloadGame(gameID: string): Promise<void> {
+  const token = getToken();
+  const res = await fetch(`${API_BASE}/api/games/${gameID}/export`, {
+    headers: token ? { 'Authorization': `Bearer ${token}` } : {},
+  });
+  if (!res.ok) throw new Error(await res.text());
+  saveBlob(await res.blob(), `codenames-${gameID}.json`);
+}
+
+// importGame adds the game of a record file to the room.
+export async function importGame(roomID: string, file: File): Promise<{ game_id: string }> {
+  const token = await ensureToken();
+  const res = await fetch(`${API_BASE}/api/rooms/${roomID}/games`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+      'Authorization': `Bearer ${token}`,
+    },
+    body: file,
+  });
+  if (!res.ok) throw new Error(await res.text());
+  return res.json();
+}
+
+// downloadSheet saves the board or key card of a game to print. Only the
+// game's spymasters may get them, so the file is fetched with the token.
+export async function downloadSheet(gameID: string, sheet: 'board' | 'key', format: 'pdf' | 'svg', patterns: boolean): Promise<void> {
+  const token = getToken();
+  const res = await fetch(`${API_BASE}/api/games/${gameID}/${sheet}.${format}?patterns=${patterns}`, {
+    headers: token ? { 'Authorization': `Bearer ${token}` } : {},
+  });
+  if (!res.ok) throw new Error(await res.text());
+  saveBlob(await res.blob(), `codenames-${sheet}.${format}`);
+}
+
+function saveBlob(blob: Blob, filename: string) {
+  const url = URL.createObjectURL(blob);
+  const link = document.createElement('a');
+  link.href = url;
+  link.download = filename;
+  link.click();
+  URL.revokeObjectURL(url);
+}
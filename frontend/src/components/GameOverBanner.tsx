@@ -1,18 +1,27 @@
+import { downloadGame } from '../api/http';
 import type { Team } from '../types';
 
 interface Props {
   winner: Team;
+  gameID: string;
   onNewGame: () => void;
 }
 
-export default function GameOverBanner({ winner, onNewGame }: Props) {
+export default function GameOverBanner({ winner, gameID, onNewGame }: Props) {
   const teamName = winner === 'red' ? 'Красные' : 'Синие';
-  const color = winner === 'red' ? '#d32f2f' : '#1976d2';
+  const color = winner === 'red' ? '#d32f2f' : winner === 'blue' ? '#1976d2' : '#616161';
+
+  const download = () => {
+    downloadGame(gameID).catch((e: Error) => {
+      alert(`Не удалось скачать: ${e.message}`);
+    });
+  };
 
   return (
     <div className="game-over-banner" style={{ borderColor: color }}>
-      <h2 style={{ color }}>Победа: {teamName}!</h2>
+      <h2 style={{ color }}>{winner ? `Победа: ${teamName}!` : 'Игра завершена'}</h2>
       <button onClick={onNewGame}>Новая игра</button>
+      <button className="download-link" onClick={download}>Скачать партию</button>
     </div>
   );
 }
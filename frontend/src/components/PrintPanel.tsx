@@ -0,0 +1,32 @@
+import { useState } from 'react';
+import { downloadSheet } from '../api/http';
+
+interface Props {
+  gameID: string;
+}
+
+export default function PrintPanel({ gameID }: Props) {
+  const [format, setFormat] = useState<'pdf' | 'svg'>('pdf');
+  const [patterns, setPatterns] = useState(false);
+
+  const download = (sheet: 'board' | 'key') => {
+    downloadSheet(gameID, sheet, format, patterns).catch((e: Error) => {
+      alert(`Не удалось скачать: ${e.message}`);
+    });
+  };
+
+  return (
+    <div className="print-panel">
+      <button onClick={() => download('board')}>Поле для печати</button>
+      <button onClick={() => download('key')}>Карта спаймастера</button>
+      <select value={format} onChange={(e) => setFormat(e.target.value as 'pdf' | 'svg')}>
+        <option value="pdf">PDF</option>
+        <option value="svg">SVG</option>
+      </select>
+      <label>
+        <input type="checkbox" checked={patterns} onChange={(e) => setPatterns(e.target.checked)} />
+        Узоры для дальтоников
+      </label>
+    </div>
+  );
+}
@@ -20,7 +20,7 @@ export default function TeamPanel({ team, players, currentPlayerID, onJoin, onSe
       </h3>
       <div className="team-players">
         {teamPlayers.map((p) => (
-          <div key={p.id} className={`team-player ${!p.is_online ? 'offline' : ''}`}>
+          <div key={p.id} className={`team-player ${p.presence !== 'online' ? p.presence : ''}`}>
             <span>{p.name}</span>
             <span className="role-badge">
               {p.role === 'spymaster' ? '🕵️' : p.role === 'operative' ? '🔍' : ''}
@@ -8,6 +8,7 @@ import ClueInput from '../components/ClueInput';
 import ClueDisplay from '../components/ClueDisplay';
 import GameOverBanner from '../components/GameOverBanner';
 import JoinTeamModal from '../components/JoinTeamModal';
+import PrintPanel from '../components/PrintPanel';
 import type { Team } from '../types';
 
 export default function GamePage() {
@@ -17,6 +18,8 @@ export default function GamePage() {
   const playerName = useGameStore((s) => s.playerName);
   const error = useGameStore((s) => s.error);
   const clearError = useGameStore((s) => s.clearError);
+  const notice = useGameStore((s) => s.notice);
+  const clearNotice = useGameStore((s) => s.clearNotice);
   const { send } = useWebSocket(roomID);
 
   // Ensure player is joined
@@ -61,15 +64,15 @@ export default function GamePage() {
   const needsTeam = currentPlayer && !currentPlayer.team;
 
   const handleJoinTeam = (team: Team) => {
-    send({ type: 'join_team', team, role: 'operative' });
+    send({ type: 'join_team', payload: { team, role: 'operative' } });
   };
 
   const handleGiveClue = (clue: string, number: number) => {
-    send({ type: 'give_clue', clue, number });
+    send({ type: 'give_clue', payload: { clue, number } });
   };
 
   const handleGuess = (cardID: string) => {
-    send({ type: 'guess_card', card_id: cardID });
+    send({ type: 'guess_card', payload: { card_id: cardID } });
   };
 
   const handleEndGuessing = () => {
@@ -89,7 +92,7 @@ export default function GamePage() {
       )}
 
       {game.phase === 'finished' && (
-        <GameOverBanner winner={game.winner} onNewGame={handleNewGame} />
+        <GameOverBanner winner={game.winner} gameID={game.id} onNewGame={handleNewGame} />
       )}
 
       {error && (
@@ -98,6 +101,12 @@ export default function GamePage() {
         </div>
       )}
 
+      {notice && (
+        <div className="notice-banner" onClick={clearNotice}>
+          {notice}
+        </div>
+      )}
+
       <div className="game-header">
         <div className="score">
           <span className="score-red">{redRemaining}</span>
@@ -128,6 +137,8 @@ export default function GamePage() {
 
       <Board cards={cards} onGuess={handleGuess} canGuess={canGuess} />
 
+      {isSpymaster && <PrintPanel gameID={game.id} />}
+
       {canEndGuessing && (
         <button className="end-guessing-btn" onClick={handleEndGuessing}>
           Закончить угадывание
@@ -138,7 +149,7 @@ export default function GamePage() {
         <div className="game-team red-team">
           <h4>Красные</h4>
           {players.filter((p) => p.team === 'red').map((p) => (
-            <span key={p.id} className={`player-tag ${!p.is_online ? 'offline' : ''}`}>
+            <span key={p.id} className={`player-tag ${p.presence !== 'online' ? p.presence : ''}`}>
               {p.name} {p.role === 'spymaster' ? '🕵️' : '🔍'}
             </span>
           ))}
@@ -146,7 +157,7 @@ export default function GamePage() {
         <div className="game-team blue-team">
           <h4>Синие</h4>
           {players.filter((p) => p.team === 'blue').map((p) => (
-            <span key={p.id} className={`player-tag ${!p.is_online ? 'offline' : ''}`}>
+            <span key={p.id} className={`player-tag ${p.presence !== 'online' ? p.presence : ''}`}>
               {p.name} {p.role === 'spymaster' ? '🕵️' : '🔍'}
             </span>
           ))}
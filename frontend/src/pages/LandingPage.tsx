@@ -15,8 +15,12 @@ export default function LandingPage() {
       setShowNameModal(true);
       return;
     }
-    const room = await createRoom();
-    navigate(`/room/${room.id}`);
+    try {
+      const room = await createRoom();
+      navigate(`/room/${room.id}`);
+    } catch (e) {
+      alert((e as Error).message);
+    }
   };
 
   const handleNameSubmit = (name: string) => {
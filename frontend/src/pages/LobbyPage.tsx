@@ -1,6 +1,6 @@
-import { useEffect, useState } from 'react';
+import { useEffect, useState, type ChangeEvent } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
-import { joinRoom } from '../api/http';
+import { importGame, joinRoom } from '../api/http';
 import { useGameStore } from '../store/gameStore';
 import { useWebSocket } from '../ws/useWebSocket';
 import TeamPanel from '../components/TeamPanel';
@@ -15,6 +15,8 @@ export default function LobbyPage() {
   const setPlayerName = useGameStore((s) => s.setPlayerName);
   const error = useGameStore((s) => s.error);
   const clearError = useGameStore((s) => s.clearError);
+  const notice = useGameStore((s) => s.notice);
+  const clearNotice = useGameStore((s) => s.clearNotice);
   const [joined, setJoined] = useState(false);
   const showNameModal = !playerName;
   const { send } = useWebSocket(joined ? roomID : undefined);
@@ -22,8 +24,8 @@ export default function LobbyPage() {
 
   useEffect(() => {
     if (!playerName || !roomID || joined) return;
-    joinRoom(roomID, playerName).then(() => setJoined(true)).catch(() => {
-      alert('Failed to join room');
+    joinRoom(roomID, playerName).then(() => setJoined(true)).catch((e: Error) => {
+      alert(e.message);
     });
   }, [roomID, playerName, joined]);
 
@@ -39,17 +41,26 @@ export default function LobbyPage() {
   };
 
   const handleJoinTeam = (team: Team) => {
-    send({ type: 'join_team', team, role: 'operative' });
+    send({ type: 'join_team', payload: { team, role: 'operative' } });
   };
 
   const handleSetRole = (role: 'spymaster' | 'operative') => {
-    send({ type: 'set_role', role });
+    send({ type: 'set_role', payload: { role } });
   };
 
   const handleStart = () => {
     send({ type: 'start_game' });
   };
 
+  const handleImport = (e: ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    e.target.value = '';
+    if (!file || !roomID) return;
+    importGame(roomID, file).catch((err: Error) => {
+      alert(`Не удалось загрузить партию: ${err.message}`);
+    });
+  };
+
   const currentPlayer = roomState?.players.find(
     (p) => p.name === playerName
   );
@@ -98,6 +109,12 @@ export default function LobbyPage() {
         </div>
       )}
 
+      {notice && (
+        <div className="notice-banner" onClick={clearNotice}>
+          {notice}
+        </div>
+      )}
+
       <div className="teams-container">
         <TeamPanel
           team="red"
@@ -127,6 +144,11 @@ export default function LobbyPage() {
       <button className="start-btn" onClick={handleStart}>
         Начать игру
       </button>
+
+      <label className="import-game">
+        Загрузить партию
+        <input type="file" accept="application/json,.json" onChange={handleImport} hidden />
+      </label>
     </div>
   );
 }
@@ -4,10 +4,13 @@ import type { RoomState, Player } from '../types';
 interface GameStore {
   roomState: RoomState | null;
   error: string | null;
+  notice: string | null;
   playerName: string | null;
   setRoomState: (state: RoomState) => void;
   setError: (error: string) => void;
   clearError: () => void;
+  setNotice: (notice: string) => void;
+  clearNotice: () => void;
   setPlayerName: (name: string) => void;
   currentPlayer: () => Player | null;
 }
@@ -15,11 +18,14 @@ interface GameStore {
 export const useGameStore = create<GameStore>((set, get) => ({
   roomState: null,
   error: null,
+  notice: null,
   playerName: localStorage.getItem('player_name'),
 
   setRoomState: (state) => set({ roomState: state, error: null }),
   setError: (error) => set({ error }),
   clearError: () => set({ error: null }),
+  setNotice: (notice) => set({ notice }),
+  clearNotice: () => set({ notice: null }),
   setPlayerName: (name) => {
     localStorage.setItem('player_name', name);
     set({ playerName: name });
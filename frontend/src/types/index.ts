@@ -1,9 +1,36 @@
 export type Team = 'red' | 'blue' | '';
 export type Role = 'spymaster' | 'operative' | '';
 export type Phase = 'lobby' | 'playing' | 'finished';
+export type Presence = 'online' | 'reconnecting' | 'offline';
+
+export type Permission = 'anyone' | 'spymasters';
+
+export interface RoomSettings {
+  variant: 'classic';
+  board: {
+    size: number;
+    first_team_cards: number;
+    second_team_cards: number;
+    assassins: number;
+  };
+  timers: {
+    clue_seconds: number;
+    guess_seconds: number;
+  };
+  language: string;
+  word_packs: string[];
+  visibility: {
+    reveal_key_on_finish: boolean;
+  };
+  permissions: {
+    edit_settings: Permission;
+    start_game: Permission;
+  };
+}
 
 export interface Room {
   id: string;
+  settings: RoomSettings;
   created_at: string;
 }
 
@@ -14,6 +41,7 @@ export interface Player {
   team: Team;
   role: Role;
   is_online: boolean;
+  presence: Presence;
 }
 
 export interface Game {
@@ -25,6 +53,7 @@ export interface Game {
   current_number: number;
   guesses_left: number;
   winner: Team;
+  version: number;
 }
 
 export interface CardView {
@@ -45,17 +74,48 @@ export interface RoomState {
   blue_cards_left: number;
 }
 
-export interface WSMessage {
-  type: string;
-  state?: RoomState;
-  error?: string;
-}
+export type ChatChannel = 'room' | 'team';
 
-export interface OutgoingMessage {
-  type: string;
-  team?: string;
-  role?: string;
-  clue?: string;
-  number?: number;
-  card_id?: string;
+export interface ChatMessage {
+  id: number;
+  room_id: string;
+  player_id: string;
+  player_name: string;
+  channel: ChatChannel;
+  team?: Team;
+  text: string;
+  created_at: string;
 }
+
+export type Reason = 'wrong_guess' | 'out_of_guesses' | 'ended_by_player' | 'ended_by_admin' | 'assassin' | 'all_cards_revealed';
+
+// Server-to-client messages of protocol v1, see GET /api/protocol/schema.
+export type WSMessage = { seq: number } & (
+  | { type: 'room_state'; payload: RoomState }
+  | { type: 'error'; payload: { message: string; code?: 'rate_limited'; retry_after_ms?: number } }
+  | { type: 'chat_message'; payload: ChatMessage }
+  | { type: 'chat_history'; payload: { channel: ChatChannel; messages: ChatMessage[] | null } }
+  | { type: 'resync' }
+  | { type: 'server_restarting' }
+  | { type: 'announcement'; payload: { message: string } }
+  | { type: 'clue_given'; payload: { player: Player; team: Team; clue: string; number: number } }
+  | { type: 'card_revealed'; payload: { player: Player; team: Team; card: CardView } }
+  | { type: 'turn_ended'; payload: { player?: Player; team: Team; reason: Reason } }
+  | { type: 'game_finished'; payload: { winner: Team; reason: Reason } }
+  | { type: 'player_joined'; payload: { player: Player } }
+  | { type: 'player_left'; payload: { player: Player } }
+);
+
+// Client-to-server messages of protocol v1.
+export type OutgoingMessage =
+  | { type: 'join_team'; payload: { team: Team; role?: Role } }
+  | { type: 'set_role'; payload: { role: Role } }
+  | { type: 'start_game' }
+  | { type: 'give_clue'; payload: { clue: string; number: number } }
+  | { type: 'guess_card'; payload: { card_id: string } }
+  | { type: 'end_guessing' }
+  | { type: 'new_game' }
+  | { type: 'update_settings'; payload: { settings: RoomSettings } }
+  | { type: 'chat_send'; payload: { channel: ChatChannel; text: string } }
+  | { type: 'chat_history'; payload: { channel: ChatChannel; before?: number; limit?: number } }
+  | { type: 'resume'; payload: { last_seq: number } };
@@ -1,17 +1,24 @@
 import { useEffect, useRef, useCallback } from 'react';
-import { getSessionID } from '../api/http';
+import { getToken, rateLimitedMessage } from '../api/http';
 import { useGameStore } from '../store/gameStore';
 import type { WSMessage, OutgoingMessage } from '../types';
 
 const RECONNECT_DELAYS = [500, 1000, 2000, 5000];
+const PROTOCOL = 'codenames.v1';
+
+// Close codes after which reconnecting is pointless.
+const CLOSE_KICKED = 4001;
+const CLOSE_ROOM_DELETED = 4004;
 
 export function useWebSocket(roomID: string | undefined) {
   const wsRef = useRef<WebSocket | null>(null);
   const roomIDRef = useRef<string | undefined>(undefined);
   const reconnectAttempt = useRef<number>(0);
+  const lastSeq = useRef<number>(0);
   const reconnectTimer = useRef<ReturnType<typeof setTimeout> | undefined>(undefined);
   const setState = useGameStore((s) => s.setRoomState);
   const setError = useGameStore((s) => s.setError);
+  const setNotice = useGameStore((s) => s.setNotice);
 
   useEffect(() => {
     if (!roomID) return;
@@ -30,15 +37,17 @@ export function useWebSocket(roomID: string | undefined) {
     }
 
     roomIDRef.current = roomID;
+    lastSeq.current = 0;
 
     function connect() {
       if (roomIDRef.current !== roomID) return;
 
       const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
       const host = import.meta.env.VITE_WS_URL || `${protocol}//${window.location.host}`;
-      const url = `${host}/ws/${roomID}?session_id=${getSessionID()}`;
+      let url = `${host}/ws/${roomID}?token=${encodeURIComponent(getToken())}`;
+      if (lastSeq.current > 0) url += `&last_seq=${lastSeq.current}`;
 
-      const ws = new WebSocket(url);
+      const ws = new WebSocket(url, [PROTOCOL]);
       wsRef.current = ws;
 
       ws.onopen = () => {
@@ -47,15 +56,39 @@ export function useWebSocket(roomID: string | undefined) {
 
       ws.onmessage = (event) => {
         const msg: WSMessage = JSON.parse(event.data);
-        if (msg.type === 'room_state' && msg.state) {
-          setState(msg.state);
-        } else if (msg.type === 'error' && msg.error) {
-          setError(msg.error);
+        if (msg.type === 'resync') {
+          lastSeq.current = msg.seq;
+          return;
+        }
+        // Replayed messages may overlap with ones already received.
+        if (msg.seq <= lastSeq.current) return;
+        lastSeq.current = msg.seq;
+        if (msg.type === 'room_state') {
+          setState(msg.payload);
+        } else if (msg.type === 'error') {
+          if (msg.payload.code === 'rate_limited') {
+            setError(rateLimitedMessage((msg.payload.retry_after_ms ?? 0) / 1000));
+          } else {
+            setError(msg.payload.message);
+          }
+        } else if (msg.type === 'server_restarting') {
+          // Cleared by the room state sent once reconnected.
+          setError('Сервер перезапускается, переподключение…');
+        } else if (msg.type === 'announcement') {
+          setNotice(msg.payload.message);
         }
       };
 
-      ws.onclose = () => {
+      ws.onclose = (event) => {
         wsRef.current = null;
+        if (event.code === CLOSE_KICKED) {
+          setError('Вас исключили из комнаты');
+          return;
+        }
+        if (event.code === CLOSE_ROOM_DELETED) {
+          setError('Комната удалена');
+          return;
+        }
         if (roomIDRef.current === roomID) {
           const delay = RECONNECT_DELAYS[Math.min(reconnectAttempt.current, RECONNECT_DELAYS.length - 1)];
           reconnectAttempt.current++;
@@ -65,7 +98,7 @@ export function useWebSocket(roomID: string | undefined) {
     }
 
     connect();
-  }, [roomID, setState, setError]);
+  }, [roomID, setState, setError, setNotice]);
 
   // Close WS on true unmount (component removed from tree)
   useEffect(() => {